@@ -1,6 +1,9 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // TODO: Go through the whole code and wrap context around errors instead of just returning them raw.
 
@@ -11,69 +14,353 @@ func NewAction(hyprctl hyprctl, dispatcher dispatcher) *Action {
 	}
 }
 
-func (a *Action) GoToWorkspace(targetIndex int, compact bool) error {
-	hyprctl, dispatcher := a.hyprctl, a.dispatcher
+// WithMaxWorkspaces returns a copy of the action that won't grow a monitor past maxWorkspaces local workspaces (0 = unlimited).
+func (a *Action) WithMaxWorkspaces(maxWorkspaces int) *Action {
+	limited := *a
+	limited.maxWorkspaces = maxWorkspaces
 
-	activeWs, err := hyprctl.GetActiveWorkspace()
+	return &limited
+}
+
+// WithMonitorSlots returns a copy of the action that encodes stable monitor slots in names instead of Hyprland's monitor IDs.
+func (a *Action) WithMonitorSlots(slots *MonitorSlots) *Action {
+	stable := *a
+	stable.slots = slots
+
+	return &stable
+}
+
+// WithLabels returns a copy of the action that shows labels in the names of local workspaces.
+func (a *Action) WithLabels(labels *Labels) *Action {
+	labeled := *a
+	labeled.labels = labels
+
+	return &labeled
+}
+
+// WithCodec returns a copy of the action that writes new names with codec.
+func (a *Action) WithCodec(codec Codec) *Action {
+	encoded := *a
+	encoded.codec = codec
+
+	return &encoded
+}
+
+// WithHistory returns a copy of the action that remembers the previous local workspace of each monitor in history.
+func (a *Action) WithHistory(history *History) *Action {
+	remembering := *a
+	remembering.history = history
+
+	return &remembering
+}
+
+// monitorSlots maps the Hyprland IDs of monitors to the slots their names encode. It returns nil when names
+// encode Hyprland's IDs directly.
+func (a *Action) monitorSlots(monitors []MonitorDTO) (map[int]int, error) {
+	if a.slots == nil {
+		return nil, nil
+	}
+
+	return a.slots.Resolve(monitors)
+}
+
+// monitorIdentity returns what the monitor with ID monitorID is known by across hotplugs, as its slot is, or ""
+// when it isn't listed.
+func (a *Action) monitorIdentity(monitors []MonitorDTO, monitorID int) string {
+	return a.slots.Identities(monitors)[monitorID]
+}
+
+// monitorNamings maps the Hyprland IDs of monitors to how the names of their local workspaces are built. Without
+// --codec, new names use the codec the monitor's workspaces are already written in.
+func (a *Action) monitorNamings(monitors []MonitorDTO, workspaces []WorkspaceDTO) (map[int]Naming, error) {
+	slots, err := a.monitorSlots(monitors)
+	if err != nil {
+		return nil, err
+	}
+
+	namings := map[int]Naming{}
+	for _, mon := range monitors {
+		codec := a.codec
+		if codec == nil {
+			codec = codecInUse(GetWorkspacesOnMonitor(workspaces, mon.ID))
+		}
+
+		namings[mon.ID] = Naming{Codec: codec, Slot: slotOf(slots, mon.ID), Labels: a.labels.For(mon)}
+	}
+
+	return namings, nil
+}
+
+// monitorNaming returns how the names of local workspaces on monitorID are built.
+func (a *Action) monitorNaming(monitors []MonitorDTO, workspaces []WorkspaceDTO, monitorID int) (Naming, error) {
+	namings, err := a.monitorNamings(monitors, workspaces)
+	if err != nil {
+		return Naming{}, err
+	}
+
+	naming, ok := namings[monitorID]
+	if !ok {
+		naming = Naming{Codec: a.codec, Slot: monitorID}
+	}
+
+	return naming, nil
+}
+
+// fetchMonitors returns the monitors for callers that don't hold a snapshot. It only queries them when slots,
+// labels or a codec, the only things that depend on them, are in use.
+func (a *Action) fetchMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	if a.slots == nil && a.labels.Empty() && a.codec == nil {
+		return nil, nil
+	}
+
+	return a.hyprctl.GetMonitors(ctx)
+}
+
+// RunCommand executes a parsed action invocation, such as goto or move.
+func (a *Action) RunCommand(ctx context.Context, cmd ActionCommand) error {
+	a = a.WithMaxWorkspaces(cmd.Globals.MaxWorkspaces)
+
+	// Labels and codec given with the command win, otherwise the daemon's own apply
+	if cmd.Globals.Labels != nil {
+		a = a.WithLabels(cmd.Globals.Labels)
+	}
+
+	if cmd.Globals.Codec != nil {
+		a = a.WithCodec(cmd.Globals.Codec)
+	}
+
+	switch cmd.Name {
+	case "goto":
+		return a.GoToWorkspaceOnMonitor(ctx, cmd.Monitor, cmd.Index, cmd.BackAndForth, cmd.Globals.Compact)
+	case "back":
+		return a.Back(ctx, cmd.Globals.Compact)
+	case "move":
+		return a.MoveToWorkspaceOnMonitor(ctx, cmd.Monitor, cmd.Index, cmd.All, cmd.Globals.Compact)
+	case "cycle":
+		return a.CycleWorkspace(ctx, cmd.Direction, cmd.Globals.Compact)
+	case "swap":
+		return a.SwapWorkspace(ctx, cmd.Index, cmd.Globals.Compact)
+	case "move-workspace":
+		return a.MoveWorkspace(ctx, cmd.Index, cmd.Direction, cmd.Globals.Compact)
+	case "move-to-monitor":
+		return a.MoveToMonitor(ctx, cmd.Monitor, cmd.Index, cmd.All, cmd.Globals.Compact)
+	case "move-workspace-to-monitor":
+		return a.MoveWorkspaceToMonitor(ctx, cmd.Monitor, cmd.Index, cmd.Globals.Compact)
+	default:
+		return fmt.Errorf("unknown command: %q", cmd.Name)
+	}
+}
+
+func (a *Action) GoToWorkspace(ctx context.Context, targetIndex int, compact bool) error {
+	return a.GoToWorkspaceOnMonitor(ctx, "", targetIndex, false, compact)
+}
+
+// GoToWorkspaceOnMonitor is GoToWorkspace for the local workspaces of the monitor FindTargetMonitor resolves
+// monitor to, or the focused monitor when monitor is empty. That monitor gets focus, even when it already shows
+// the target. With backAndForth, targeting the active workspace goes back to the previous one instead.
+func (a *Action) GoToWorkspaceOnMonitor(ctx context.Context, monitor string, targetIndex int, backAndForth bool, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
 	if err != nil {
 		return err
 	}
 
+	activeWs := snap.ActiveWorkspace
 	monitorID := activeWs.MonitorID
-	sortedLocalWs, err := GetSortedWorkspacesOnMonitor(hyprctl, monitorID)
+	currentWsID := activeWs.ID
+
+	var focusCmds []DispatchCmd
+	if monitor != "" {
+		mon, err := FindTargetMonitor(snap.Monitors, monitorID, monitor)
+		if err != nil {
+			return err
+		}
+
+		// Hyprland creates workspaces on the focused monitor, so focus has to get there first
+		if mon.ID != monitorID {
+			monitorID, currentWsID = mon.ID, mon.ActiveWorkspace.ID
+			focusCmds = append(focusCmds, FocusMonitorCmd(mon.ID))
+		}
+	}
+
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+
+	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, currentWsID)
+	if currentWsIndex == -1 {
+		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", currentWsID)
+	}
+
+	targetWsIndex, _ := DecideTargetWorkspaceIndex(currentWsIndex, targetIndex, sortedLocalWs)
+	targetWsIndex = LimitTargetWorkspaceIndex(targetWsIndex, len(sortedLocalWs), a.maxWorkspaces)
+
+	if currentWsIndex == targetWsIndex {
+		// Only the focused monitor's workspace is active, another one just gets focus
+		if backAndForth && len(focusCmds) == 0 {
+			return a.back(ctx, snap, sortedLocalWs, currentWsIndex, compact)
+		}
+
+		// No-op, apart from focusing the monitor
+		return dispatchBatch(ctx, a.dispatcher, focusCmds)
+	}
+
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
 	if err != nil {
 		return err
 	}
 
+	if err := a.switchToIndex(ctx, sortedLocalWs, naming, targetWsIndex, compact, focusCmds...); err != nil {
+		return err
+	}
+
+	a.remember(snap.Monitors, monitorID, currentWsID, currentWsIndex)
+	return nil
+}
+
+// Back returns the focused monitor to the local workspace it showed before the last goto, cycle or back. It is
+// a no-op when there is none.
+func (a *Action) Back(ctx context.Context, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	activeWs := snap.ActiveWorkspace
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, activeWs.MonitorID)
+
 	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, activeWs.ID)
 	if currentWsIndex == -1 {
 		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", activeWs.ID)
 	}
 
-	targetWsIndex, _ := DecideTargetWorkspaceIndex(currentWsIndex, targetIndex, sortedLocalWs)
+	return a.back(ctx, snap, sortedLocalWs, currentWsIndex, compact)
+}
+
+func (a *Action) back(ctx context.Context, snap Snapshot, sortedLocalWs []WorkspaceDTO, currentWsIndex int, compact bool) error {
+	monitorID := snap.ActiveWorkspace.MonitorID
+
+	previous, ok, err := a.history.Previous(a.monitorIdentity(snap.Monitors, monitorID))
+	if err != nil || !ok {
+		return err
+	}
+
+	// Renames keep the ID, but Hyprland destroys a workspace left empty, which is then recreated at its index
+	targetWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, previous.ID)
+	if targetWsIndex == -1 {
+		targetWsIndex, _ = DecideTargetWorkspaceIndex(currentWsIndex, previous.Index, sortedLocalWs)
+		targetWsIndex = LimitTargetWorkspaceIndex(targetWsIndex, len(sortedLocalWs), a.maxWorkspaces)
+	}
 
 	if currentWsIndex == targetWsIndex {
 		// No-op
 		return nil
 	}
 
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.switchToIndex(ctx, sortedLocalWs, naming, targetWsIndex, compact); err != nil {
+		return err
+	}
+
+	a.remember(snap.Monitors, monitorID, snap.ActiveWorkspace.ID, currentWsIndex)
+	return nil
+}
+
+// remember records the workspace with ID wsID at index as the one monitorID showed before the switch away from it.
+// The switch already happened, so a history that can't be written only costs back its memory of it.
+func (a *Action) remember(monitors []MonitorDTO, monitorID, wsID, index int) {
+	_ = a.history.Record(a.monitorIdentity(monitors, monitorID), PreviousWorkspace{ID: wsID, Index: index})
+}
+
+// existingTargetName returns the name the existing workspace ws at index goes by once compaction, if requested,
+// is done. Without compaction it keeps its name, and with it the label and codec it may carry.
+func existingTargetName(ws WorkspaceDTO, naming Naming, index int, compact bool) (string, error) {
+	if !compact {
+		return ws.Name, nil
+	}
+
+	return naming.Rename(ws.Name, index)
+}
+
+// switchToIndex focuses the workspace at targetWsIndex of sortedLocalWs, compacting the monitor first if requested.
+// naming is how the monitor's names are built, and focusCmds go right before the switch.
+func (a *Action) switchToIndex(ctx context.Context, sortedLocalWs []WorkspaceDTO, naming Naming, targetWsIndex int, compact bool, focusCmds ...DispatchCmd) error {
+	targetWsName, err := naming.Name(targetWsIndex)
+	if err != nil {
+		return err
+	}
+
+	var cmds []DispatchCmd
 	if compact {
-		targetWsName, err := GetZeroWidthNameFromIndex(monitorID, targetWsIndex)
-		if err != nil {
+		if cmds, err = GetCompactionCmds(sortedLocalWs, naming, false); err != nil {
 			return err
 		}
+	}
 
-		err = CompactLocalWorkspacesOnMonitor(a, monitorID, false)
-		if err != nil {
+	if targetWsIndex < len(sortedLocalWs) {
+		if targetWsName, err = existingTargetName(sortedLocalWs[targetWsIndex], naming, targetWsIndex, compact); err != nil {
 			return err
 		}
+	}
 
-		return dispatcher.GoToWorkspace(targetWsName)
+	cmds = append(cmds, focusCmds...)
+	if len(cmds) == 0 {
+		return a.dispatcher.GoToWorkspace(ctx, targetWsName)
 	}
 
-	return dispatcher.GoToWorkspace(sortedLocalWs[targetWsIndex].Name)
+	// Renames and the switch land together, so the target name already refers to the compacted slot
+	return a.dispatcher.Batch(ctx, append(cmds, GoToWorkspaceCmd(targetWsName)))
 }
 
-func (a *Action) MoveToWorkspace(targetIndex int, all bool, compact bool) error {
-	hyprctl, dispatcher := a.hyprctl, a.dispatcher
-
-	activeWs, err := hyprctl.GetActiveWorkspace()
+func (a *Action) MoveToWorkspace(ctx context.Context, targetIndex int, all bool, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
 	if err != nil {
 		return err
 	}
 
-	monitorID := activeWs.MonitorID
-	sortedLocalWs, err := GetSortedWorkspacesOnMonitor(hyprctl, monitorID)
+	return a.moveToWorkspace(ctx, snap, targetIndex, all, compact)
+}
+
+// MoveToWorkspaceOnMonitor is MoveToWorkspace for the local workspaces of the monitor FindTargetMonitor resolves
+// monitor to, or the focused monitor when monitor is empty. Another monitor is handled like MoveToMonitor does.
+func (a *Action) MoveToWorkspaceOnMonitor(ctx context.Context, monitor string, targetIndex int, all bool, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
 	if err != nil {
 		return err
 	}
 
+	if monitor != "" {
+		mon, err := FindTargetMonitor(snap.Monitors, snap.ActiveWorkspace.MonitorID, monitor)
+		if err != nil {
+			return err
+		}
+
+		if mon.ID != snap.ActiveWorkspace.MonitorID {
+			return a.moveToMonitor(ctx, snap, mon, targetIndex, all, compact)
+		}
+	}
+
+	return a.moveToWorkspace(ctx, snap, targetIndex, all, compact)
+}
+
+func (a *Action) moveToWorkspace(ctx context.Context, snap Snapshot, targetIndex int, all bool, compact bool) error {
+	activeWs := snap.ActiveWorkspace
+	monitorID := activeWs.MonitorID
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+
 	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, activeWs.ID)
 	if currentWsIndex == -1 {
 		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", activeWs.ID)
 	}
 
 	targetWsIndex, _ := DecideTargetWorkspaceIndex(currentWsIndex, targetIndex, sortedLocalWs)
+
+	// Emptying the source frees up its slot, so only a move that leaves windows behind grows the monitor
+	if activeWs.WindowsCount > 1 && !all {
+		targetWsIndex = LimitTargetWorkspaceIndex(targetWsIndex, len(sortedLocalWs), a.maxWorkspaces)
+	}
+
 	if currentWsIndex == targetWsIndex {
 		// No-op
 		return nil
@@ -84,110 +371,367 @@ func (a *Action) MoveToWorkspace(targetIndex int, all bool, compact bool) error
 		return nil
 	}
 
-	targetWsName, err := GetZeroWidthNameFromIndex(monitorID, targetWsIndex)
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
 	if err != nil {
 		return err
 	}
 
-	if all && activeWs.WindowsCount > 1 {
-		clients, err := hyprctl.GetClientsInWorkspace(activeWs.ID)
-		if err != nil {
-			return err
-		}
+	targetWsName, err := naming.Name(targetWsIndex)
+	if err != nil {
+		return err
+	}
+
+	// An existing target keeps its name until compaction, which may carry another label or codec
+	if targetWsIndex < len(sortedLocalWs) {
+		targetWsName = sortedLocalWs[targetWsIndex].Name
+	}
+
+	var renames []DispatchCmd
+
+	// Hyprland destroys the source once its last window leaves, so the gap to close is known up front and the
+	// renames land in the same batch as the move
+	if compact && (activeWs.WindowsCount <= 1 || all) {
+		remaining := make([]WorkspaceDTO, 0, len(sortedLocalWs))
+		remaining = append(remaining, sortedLocalWs[:currentWsIndex]...)
+		remaining = append(remaining, sortedLocalWs[currentWsIndex+1:]...)
 
-		for _, client := range clients {
-			err := dispatcher.MoveAddrToWorkspace(targetWsName, client.Address)
-			if err != nil {
+		if targetWsIndex >= len(sortedLocalWs) {
+			// A new workspace's ID isn't known before it exists, so the source takes its place instead, under the
+			// name the new workspace would have ended up with
+			reordered := append(remaining, WorkspaceDTO{ID: activeWs.ID, Name: targetWsName})
+			if renames, err = GetReorderCmds(sortedLocalWs, reordered, naming, true); err != nil {
 				return err
 			}
+
+			return dispatchBatch(ctx, a.dispatcher, renames)
 		}
-	} else {
-		// This approach would not allow us to move clients to workspaces that don't exist yet. Hyprctl limitation?
-		// err = dispatcher.MoveToWorkspace(targetWsName)
-		// if err != nil {
-		// 	return err
-		// }
 
-		activeWindow, err := hyprctl.GetActiveWindow()
-		if err != nil {
+		if renames, err = GetCompactionCmds(remaining, naming, false); err != nil {
 			return err
 		}
+	}
 
-		err = dispatcher.MoveAddrToWorkspace(targetWsName, activeWindow.Address)
-		if err != nil {
-			return err
+	if all && activeWs.WindowsCount > 1 {
+		var cmds []DispatchCmd
+		for _, client := range snap.ClientsInWorkspace(activeWs.ID) {
+			cmds = append(cmds, MoveAddrToWorkspaceCmd(targetWsName, client.Address))
 		}
+
+		return dispatchBatch(ctx, a.dispatcher, append(cmds, renames...))
+	}
+
+	if len(renames) > 0 {
+		return a.dispatcher.Batch(ctx, append([]DispatchCmd{MoveAddrToWorkspaceCmd(targetWsName, snap.ActiveWindow.Address)}, renames...))
+	}
+
+	// This approach would not allow us to move clients to workspaces that don't exist yet. Hyprctl limitation?
+	// err = dispatcher.MoveToWorkspace(targetWsName)
+
+	return a.dispatcher.MoveAddrToWorkspace(ctx, targetWsName, snap.ActiveWindow.Address)
+}
+
+func (a *Action) CycleWorkspace(ctx context.Context, direction string, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	activeWs := snap.ActiveWorkspace
+	monitorID := activeWs.MonitorID
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+
+	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, activeWs.ID)
+	if currentWsIndex == -1 {
+		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", activeWs.ID)
+	}
+
+	dir := 1
+	if direction == "prev" {
+		dir = -1
 	}
 
-	if compact && (activeWs.WindowsCount == 1 || all) {
-		err := CompactLocalWorkspacesOnMonitor(a, monitorID, false)
+	targetWsIndex, _ := DecideTargetWorkspaceIndex(currentWsIndex, currentWsIndex+dir, sortedLocalWs)
+	targetWsIndex = LimitTargetWorkspaceIndex(targetWsIndex, len(sortedLocalWs), a.maxWorkspaces)
+	if currentWsIndex == targetWsIndex {
+		// No-op
+		return nil
+	}
+
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.switchToIndex(ctx, sortedLocalWs, naming, targetWsIndex, compact); err != nil {
+		return err
+	}
+
+	a.remember(snap.Monitors, monitorID, activeWs.ID, currentWsIndex)
+	return nil
+}
+
+func (a *Action) InitWorkspaces(ctx context.Context) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	namings, err := a.monitorNamings(snap.Monitors, snap.Workspaces)
+	if err != nil {
+		return err
+	}
+
+	var cmds []DispatchCmd
+	for _, mon := range snap.Monitors {
+		monCmds, err := GetCompactionCmds(GetSortedWorkspacesOnMonitor(snap.Workspaces, mon.ID), namings[mon.ID], true)
 		if err != nil {
 			return err
 		}
+
+		cmds = append(cmds, monCmds...)
 	}
 
-	return nil
+	return dispatchBatch(ctx, a.dispatcher, cmds)
 }
 
-func (a *Action) CycleWorkspace(direction string, compact bool) error {
-	hyprctl, dispatcher := a.hyprctl, a.dispatcher
+// MigrateWorkspaces re-encodes every local workspace name written with from in to, in place.
+func (a *Action) MigrateWorkspaces(ctx context.Context, from, to Codec) error {
+	workspaces, err := a.hyprctl.GetWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
 
-	activeWs, err := hyprctl.GetActiveWorkspace()
+	cmds, err := GetMigrationCmds(workspaces, from, to)
 	if err != nil {
 		return err
 	}
 
+	return dispatchBatch(ctx, a.dispatcher, cmds)
+}
+
+// SwapWorkspace exchanges the active workspace with the local workspace at targetIndex on the same monitor, or the
+// last one if there are fewer. Windows stay with their workspace and focus stays on the active one, which now
+// sits at targetIndex.
+func (a *Action) SwapWorkspace(ctx context.Context, targetIndex int, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	activeWs := snap.ActiveWorkspace
 	monitorID := activeWs.MonitorID
-	sortedLocalWs, err := GetSortedWorkspacesOnMonitor(hyprctl, monitorID)
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+
+	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, activeWs.ID)
+	if currentWsIndex == -1 {
+		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", activeWs.ID)
+	}
+
+	// There is nothing to swap with past the last workspace
+	targetWsIndex := min(targetIndex, len(sortedLocalWs)-1)
+	if currentWsIndex == targetWsIndex {
+		// No-op
+		return nil
+	}
+
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
 	if err != nil {
 		return err
 	}
 
+	cmds, err := GetSwapCmds(sortedLocalWs, naming, currentWsIndex, targetWsIndex, compact)
+	if err != nil {
+		return err
+	}
+
+	return dispatchBatch(ctx, a.dispatcher, cmds)
+}
+
+// MoveWorkspace moves the active workspace to the local position targetIndex on its monitor, or one position
+// towards direction ("left" or "right") when it is set, shifting the workspaces in between. Windows stay with their
+// workspace and focus stays on the active one.
+func (a *Action) MoveWorkspace(ctx context.Context, targetIndex int, direction string, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	activeWs := snap.ActiveWorkspace
+	monitorID := activeWs.MonitorID
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+
 	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, activeWs.ID)
 	if currentWsIndex == -1 {
 		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", activeWs.ID)
 	}
 
-	dir := 1
-	if direction == "prev" {
-		dir = -1
+	switch direction {
+	case "left":
+		targetIndex = currentWsIndex - 1
+	case "right":
+		targetIndex = currentWsIndex + 1
 	}
 
-	targetWsIndex, _ := DecideTargetWorkspaceIndex(currentWsIndex, currentWsIndex+dir, sortedLocalWs)
+	// A workspace can only move between the existing ones
+	targetWsIndex := max(0, min(targetIndex, len(sortedLocalWs)-1))
 	if currentWsIndex == targetWsIndex {
 		// No-op
 		return nil
 	}
 
-	if compact {
-		targetWsName, err := GetZeroWidthNameFromIndex(monitorID, targetWsIndex)
-		if err != nil {
-			return err
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
+	if err != nil {
+		return err
+	}
+
+	cmds, err := GetMoveWorkspaceCmds(sortedLocalWs, naming, currentWsIndex, targetWsIndex, compact)
+	if err != nil {
+		return err
+	}
+
+	return dispatchBatch(ctx, a.dispatcher, cmds)
+}
+
+// MoveToMonitor moves the active window, or all windows of the active workspace, to another monitor, as resolved
+// by FindTargetMonitor. They land on the workspace that monitor shows, or on its local workspace at targetIndex
+// if it is non-negative, which is created when it doesn't exist yet. Focus follows the windows.
+func (a *Action) MoveToMonitor(ctx context.Context, target string, targetIndex int, all bool, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetMon, err := FindTargetMonitor(snap.Monitors, snap.ActiveWorkspace.MonitorID, target)
+	if err != nil {
+		return err
+	}
+
+	return a.moveToMonitor(ctx, snap, targetMon, targetIndex, all, compact)
+}
+
+func (a *Action) moveToMonitor(ctx context.Context, snap Snapshot, targetMon MonitorDTO, targetIndex int, all bool, compact bool) error {
+	activeWs := snap.ActiveWorkspace
+
+	var addresses []string
+	if all {
+		for _, client := range snap.ClientsInWorkspace(activeWs.ID) {
+			addresses = append(addresses, client.Address)
 		}
+	} else if snap.ActiveWindow.Address != "" {
+		addresses = append(addresses, snap.ActiveWindow.Address)
+	}
 
-		err = CompactLocalWorkspacesOnMonitor(a, monitorID, false)
+	if targetMon.ID == activeWs.MonitorID || len(addresses) == 0 {
+		// No-op
+		return nil
+	}
+
+	var cmds []DispatchCmd
+	targetWsName := targetMon.ActiveWorkspace.Name
+	if targetIndex >= 0 {
+		var err error
+		cmds, targetWsName, err = a.prepareWorkspaceOnMonitor(snap, targetMon.ID, targetIndex, compact)
 		if err != nil {
 			return err
 		}
+	}
+
+	for _, address := range addresses {
+		cmds = append(cmds, MoveAddrToWorkspaceCmd(targetWsName, address))
+	}
+
+	return dispatchBatch(ctx, a.dispatcher, cmds)
+}
+
+// prepareWorkspaceOnMonitor returns the name of the local workspace at targetIndex on monitorID, and the
+// dispatches that must come first: compaction if requested, and creating the workspace on that monitor if it
+// doesn't exist, since Hyprland creates new workspaces on the focused monitor.
+func (a *Action) prepareWorkspaceOnMonitor(snap Snapshot, monitorID, targetIndex int, compact bool) ([]DispatchCmd, string, error) {
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+	targetWsIndex := min(targetIndex, len(sortedLocalWs))
+	targetWsIndex = LimitTargetWorkspaceIndex(targetWsIndex, len(sortedLocalWs), a.maxWorkspaces)
+
+	naming, err := a.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var cmds []DispatchCmd
+	if compact {
+		if cmds, err = GetCompactionCmds(sortedLocalWs, naming, false); err != nil {
+			return nil, "", err
+		}
+	}
 
-		return dispatcher.GoToWorkspace(targetWsName)
+	targetWsName, err := naming.Name(targetWsIndex)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return dispatcher.GoToWorkspace(sortedLocalWs[targetWsIndex].Name)
+	if targetWsIndex < len(sortedLocalWs) {
+		targetWsName, err = existingTargetName(sortedLocalWs[targetWsIndex], naming, targetWsIndex, compact)
+		return cmds, targetWsName, err
+	}
+
+	return append(cmds, FocusMonitorCmd(monitorID), GoToWorkspaceCmd(targetWsName)), targetWsName, nil
 }
 
-func (a *Action) InitWorkspaces() error {
-	monitors, err := a.hyprctl.GetMonitors()
+// MoveWorkspaceToMonitor moves the active workspace, windows and all, to another monitor as resolved by
+// FindTargetMonitor. It is renamed into the target monitor's scheme at targetIndex, or after the last local
+// workspace there when targetIndex is negative, and focus follows it. With compact, the source monitor closes the
+// gap, including the workspace Hyprland creates there if the moved one was its only workspace.
+func (a *Action) MoveWorkspaceToMonitor(ctx context.Context, target string, targetIndex int, compact bool) error {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, mon := range monitors {
-		err := CompactLocalWorkspacesOnMonitor(a, mon.ID, true)
+	activeWs := snap.ActiveWorkspace
+	monitorID := activeWs.MonitorID
+	sortedLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID)
+
+	currentWsIndex := GetWorkspaceIndexOnList(sortedLocalWs, activeWs.ID)
+	if currentWsIndex == -1 {
+		return fmt.Errorf("current workspace (ID %d) not found in local workspace list", activeWs.ID)
+	}
+
+	targetMon, err := FindTargetMonitor(snap.Monitors, monitorID, target)
+	if err != nil {
+		return err
+	}
+
+	if targetMon.ID == monitorID {
+		// No-op
+		return nil
+	}
+
+	namings, err := a.monitorNamings(snap.Monitors, snap.Workspaces)
+	if err != nil {
+		return err
+	}
+
+	targetLocalWs := GetSortedWorkspacesOnMonitor(snap.Workspaces, targetMon.ID)
+	if targetIndex < 0 {
+		targetIndex = len(targetLocalWs)
+	}
+
+	cmds, newName, err := GetMoveToMonitorCmds(sortedLocalWs, namingOf(namings, monitorID), currentWsIndex, targetLocalWs, namingOf(namings, targetMon.ID), targetIndex, compact)
+	if err != nil {
+		return err
+	}
+
+	cmds = append(cmds, MoveWorkspaceToMonitorCmd(activeWs.ID, targetMon.ID))
+
+	// A monitor can't be left without a workspace, so Hyprland creates one with a plain name in its place. Going
+	// to the source's first local name there instead replaces it with a local one, which Hyprland then destroys.
+	if compact && len(sortedLocalWs) == 1 {
+		placeholder, err := namingOf(namings, monitorID).Name(0)
 		if err != nil {
 			return err
 		}
+
+		cmds = append(cmds, FocusMonitorCmd(monitorID), GoToWorkspaceCmd(placeholder))
 	}
 
-	return nil
+	return dispatchBatch(ctx, a.dispatcher, append(cmds, GoToWorkspaceCmd(newName)))
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBack_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	err := NewAction(hypr, dispatcher).Back(context.Background(), true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestBack_HistoryFollowsMonitorIdentity(t *testing.T) {
+	dir := t.TempDir()
+	history := NewHistory(filepath.Join(dir, "history.json"))
+	slots := NewMonitorSlots(filepath.Join(dir, "monitor-slots.json"), MonitorIdentityDescription)
+	action := NewAction(nil, nil).WithMonitorSlots(slots).WithHistory(history)
+
+	action.remember([]MonitorDTO{{ID: 0, Name: "DP-1", Description: "Dell Inc. DELL U2720Q"}}, 0, -1338, 1)
+
+	// Docked again, the monitor comes back on another connector
+	previous, ok, err := history.Previous(action.monitorIdentity([]MonitorDTO{{ID: 1, Name: "DP-3", Description: "Dell Inc. DELL U2720Q"}}, 1))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, PreviousWorkspace{ID: -1338, Index: 1}, previous)
+}
+
+func TestBack_Simulated_UnwritableHistoryDoesNotFailTheSwitch(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	path := filepath.Join(t.TempDir(), "history.json")
+	// A directory in the way of the lock file makes every save fail
+	require.NoError(t, os.Mkdir(path+".lock", 0o700))
+	action := NewAction(sim, sim).WithHistory(NewHistory(path))
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 2, true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+	assert.NoFileExists(t, path)
+}
+
+func TestBack_Simulated_NothingRecordedIsNoOp(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	action := NewAction(sim, sim).WithHistory(NewHistory(filepath.Join(t.TempDir(), "history.json")))
+
+	require.NoError(t, action.Back(context.Background(), true))
+	assert.Empty(t, sim.Dispatched)
+}
+
+func TestBack_Simulated_TogglesBetweenWorkspaces(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	action := NewAction(sim, sim).WithHistory(NewHistory(filepath.Join(t.TempDir(), "history.json")))
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 2, true))
+	require.NoError(t, action.Back(context.Background(), true))
+	assert.Equal(t, "1\u200b\u200b", sim.ActiveWorkspaceName())
+
+	require.NoError(t, action.Back(context.Background(), true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+
+	require.NoError(t, action.CycleWorkspace(context.Background(), "prev", true))
+	require.NoError(t, action.Back(context.Background(), true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+}
+
+func TestBack_Simulated_FollowsWorkspaceAcrossRenames(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	action := NewAction(sim, sim).WithHistory(NewHistory(filepath.Join(t.TempDir(), "history.json")))
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 1, true))
+	require.NoError(t, action.GoToWorkspace(context.Background(), 2, true))
+
+	// Emptying and leaving workspace 2 compacts workspace 3 into position 2
+	require.NoError(t, action.GoToWorkspace(context.Background(), 1, true))
+	require.NoError(t, action.MoveToWorkspace(context.Background(), 0, true, true))
+	require.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+
+	require.NoError(t, action.Back(context.Background(), true))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName(), "the workspace 2 was left for, now renamed")
+}
+
+func TestBack_Simulated_RecreatesDestroyedWorkspace(t *testing.T) {
+	sim := simulatedMonitorWith(t, 1)
+	action := NewAction(sim, sim).WithHistory(NewHistory(filepath.Join(t.TempDir(), "history.json")))
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 1, true))
+	require.NoError(t, action.Back(context.Background(), true))
+	require.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0), "the new workspace was empty")
+
+	require.NoError(t, action.Back(context.Background(), true))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+}
+
+func TestGoToWorkspace_Simulated_BackAndForth(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	action := NewAction(sim, sim).WithHistory(NewHistory(filepath.Join(t.TempDir(), "history.json")))
+
+	require.NoError(t, action.GoToWorkspaceOnMonitor(context.Background(), "", 2, true, true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+
+	require.NoError(t, action.GoToWorkspaceOnMonitor(context.Background(), "", 2, true, true))
+	assert.Equal(t, "1\u200b\u200b", sim.ActiveWorkspaceName())
+
+	// Without it, the active workspace stays
+	require.NoError(t, action.GoToWorkspaceOnMonitor(context.Background(), "", 0, false, true))
+	assert.Equal(t, "1\u200b\u200b", sim.ActiveWorkspaceName())
+}
+
+func TestBack_DryRunKeepsHistory(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	path := filepath.Join(t.TempDir(), "history.json")
+	action := NewAction(sim, sim).WithHistory(NewHistory(path))
+
+	plan, err := RunPlanned(action, func(action *Action) error {
+		return action.GoToWorkspace(context.Background(), 2, true)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{GoToWorkspaceCmd("3\u200b\u200d")}, plan)
+
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCycleWorkspace_NoActiveWorkspaceError(t *testing.T) {
@@ -12,25 +14,10 @@ func TestCycleWorkspace_NoActiveWorkspaceError(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	hypr.On("GetActiveWorkspace").Return(WorkspaceDTO{}, assert.AnError)
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
-	assert.Error(t, err)
-}
-
-func TestCycleWorkspace_GetWorkspacesError(t *testing.T) {
-	hypr := new(mockHyprctl)
-	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
-	defer dispatcher.AssertExpectations(t)
-
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
-
-	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
+	err := action.CycleWorkspace(context.Background(), "next", true)
 	assert.Error(t, err)
 }
 
@@ -41,15 +28,17 @@ func TestCycleWorkspace_CurrentNotOnListError(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 42, Name: "42\u200b\u200c", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
+	err := action.CycleWorkspace(context.Background(), "next", true)
 	assert.Error(t, err)
 }
 
@@ -60,14 +49,16 @@ func TestCycleWorkspace_ReturnsEarlyWhenPrevOnFirst(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("prev", true)
+	err := action.CycleWorkspace(context.Background(), "prev", true)
 	assert.NoError(t, err)
 }
 
@@ -78,18 +69,20 @@ func TestCycleWorkspace_CompactTrue_Success(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
 	}, nil)
 
 	// Cycling next from index 0 -> index 1
 	dispatcher.On("GoToWorkspace", "2\u200b\u200c").Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
+	err := action.CycleWorkspace(context.Background(), "next", true)
 	assert.NoError(t, err)
 }
 
@@ -100,16 +93,18 @@ func TestCycleWorkspace_DispatcherError(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		},
 	}, nil)
 
 	dispatcher.On("GoToWorkspace", "2\u200b\u200c").Return(assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
+	err := action.CycleWorkspace(context.Background(), "next", true)
 	assert.Error(t, err)
 }
 
@@ -120,16 +115,18 @@ func TestCycleWorkspace_CompactFalse_UsesExistingName(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		},
 	}, nil)
 
 	dispatcher.On("GoToWorkspace", "2\u200b\u200c").Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", false)
+	err := action.CycleWorkspace(context.Background(), "next", false)
 	assert.NoError(t, err)
 }
 
@@ -139,15 +136,17 @@ func TestCycleWorkspace_ZeroWidthNameError(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 999}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 999},
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: -1}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: -1},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
+	err := action.CycleWorkspace(context.Background(), "next", true)
 	assert.Error(t, err)
 }
 
@@ -158,17 +157,18 @@ func TestCycleWorkspace_CompactionError(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	// First call for sorted list
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-	}, nil).Once()
-	// Second call for compaction fails
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
+	// A foreign name cannot be compacted without fixNames
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "ws-3", MonitorID: 0},
+		},
+	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", true)
+	err := action.CycleWorkspace(context.Background(), "next", true)
 	assert.Error(t, err)
 }
 
@@ -179,15 +179,40 @@ func TestCycleWorkspace_CompactFalse_DispatcherError(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		},
 	}, nil)
 
 	dispatcher.On("GoToWorkspace", "2\u200b\u200c").Return(assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.CycleWorkspace("next", false)
+	err := action.CycleWorkspace(context.Background(), "next", false)
 	assert.Error(t, err)
 }
+
+func TestCycleWorkspace_Simulated_GapLeftByEmptyWorkspaceClosesOnNextAction(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	second := sim.AddWorkspace(0, "2\u200b\u200c")
+	third := sim.AddWorkspace(0, "3\u200b\u200d")
+	sim.AddClient(first)
+	window := sim.AddClient(third)
+	sim.Focus(second)
+
+	action := NewAction(sim, sim)
+
+	// The empty second workspace is only destroyed once we have left it, after compaction was planned
+	require.NoError(t, action.CycleWorkspace(context.Background(), "next", true))
+	assert.Equal(t, []string{"1\u200b\u200b", "3\u200b\u200d"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+
+	require.NoError(t, action.CycleWorkspace(context.Background(), "prev", true))
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, "1\u200b\u200b", sim.ActiveWorkspaceName())
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKirtle/hypr-local-workspaces/internal/simulator"
 )
 
 func TestGoToWorkspaceDispatchesToRequestedWorkspace(t *testing.T) {
@@ -14,13 +17,15 @@ func TestGoToWorkspaceDispatchesToRequestedWorkspace(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
-		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
-		{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+			{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
+			{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+		},
 	}, nil)
 
 	dispatcher.On("GoToWorkspace", "3\u200b\u200d").Return(nil)
@@ -28,7 +33,7 @@ func TestGoToWorkspaceDispatchesToRequestedWorkspace(t *testing.T) {
 	action := NewAction(hypr, dispatcher)
 	targetIndex := 2
 
-	err := action.GoToWorkspace(targetIndex, true)
+	err := action.GoToWorkspace(context.Background(), targetIndex, true)
 
 	assert.NoError(t, err)
 }
@@ -39,29 +44,11 @@ func TestGoToWorkspacePropagatesNoActiveWorkspace(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	hypr.On("GetActiveWorkspace").Return(WorkspaceDTO{}, assert.AnError)
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
 	targetIndex := 2
-	err := action.GoToWorkspace(targetIndex, true)
-
-	assert.Error(t, err)
-}
-
-func TestGoToWorkspacePropagatesGetSortedWorkspacesError(t *testing.T) {
-	hypr := new(mockHyprctl)
-	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
-	defer dispatcher.AssertExpectations(t)
-
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
-
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
-
-	action := NewAction(hypr, dispatcher)
-	targetIndex := 2
-	err := action.GoToWorkspace(targetIndex, true)
+	err := action.GoToWorkspace(context.Background(), targetIndex, true)
 
 	assert.Error(t, err)
 }
@@ -74,18 +61,20 @@ func TestGoToWorkspacePropagatesActiveWorkspaceNotOnSortedList(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 42, Name: "42\u200b\u200c", MonitorID: 0}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
-		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
-		{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+			{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
+			{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
 	targetIndex := 2
-	err := action.GoToWorkspace(targetIndex, true)
+	err := action.GoToWorkspace(context.Background(), targetIndex, true)
 
 	assert.Error(t, err)
 }
@@ -98,18 +87,20 @@ func TestGoToWorkspaceReturnsEarlyWhenTargetEqualsCurrent(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
-		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
-		{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+			{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
+			{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
 	targetIndex := 1
-	err := action.GoToWorkspace(targetIndex, true)
+	err := action.GoToWorkspace(context.Background(), targetIndex, true)
 
 	assert.NoError(t, err)
 }
@@ -120,20 +111,22 @@ func TestGoToWorkspacePropagatesGetZeroWidthNameFromIndexError(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 999}
-
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 999},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 999},
-		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
-		{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: -1}
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: -1},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: -1},
+			{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
+			{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
 	targetIndex := 0
-	err := action.GoToWorkspace(targetIndex, true)
+	err := action.GoToWorkspace(context.Background(), targetIndex, true)
 
 	assert.Error(t, err)
 }
@@ -146,20 +139,22 @@ func TestGoToWorkspacePropagatesDispatcherError(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
-		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
-		{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+			{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
+			{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
+		},
 	}, nil)
 
 	dispatcher.On("GoToWorkspace", "3\u200b\u200d").Return(assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
 	targetIndex := 2
-	err := action.GoToWorkspace(targetIndex, true)
+	err := action.GoToWorkspace(context.Background(), targetIndex, true)
 
 	assert.Error(t, err)
 }
@@ -172,11 +167,13 @@ func TestGoToWorkspace_CompactFalse_UsesExistingName(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
 	}, nil)
 
 	// When compact=false, should use name from sorted list directly
@@ -184,7 +181,7 @@ func TestGoToWorkspace_CompactFalse_UsesExistingName(t *testing.T) {
 
 	action := NewAction(hypr, dispatcher)
 	// targetIndex 1 (second workspace), current is at index 0
-	err := action.GoToWorkspace(1, false)
+	err := action.GoToWorkspace(context.Background(), 1, false)
 
 	assert.NoError(t, err)
 }
@@ -196,16 +193,197 @@ func TestGoToWorkspace_CompactionError(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	// First call for sorted list succeeds
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-	}, nil).Once()
-	// Second call during compaction fails
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
+	// A foreign name cannot be compacted without fixNames
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "ws-3", MonitorID: 0},
+		},
+	}, nil)
+
+	action := NewAction(hypr, dispatcher)
+	err := action.GoToWorkspace(context.Background(), 1, true)
+	assert.Error(t, err)
+}
+
+func TestGoToWorkspace_CompactionAndSwitchLandInOneBatch(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 1}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0, WindowsCount: 1},
+			{ID: 10, Name: "6\u200b\u2061", MonitorID: 0, WindowsCount: 1},
+		},
+	}, nil)
+
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+		RenameWorkspaceCmd(10, "3\u200b\u200d"),
+		GoToWorkspaceCmd("3\u200b\u200d"),
+	}).Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.GoToWorkspace(1, true)
+	err := action.GoToWorkspace(context.Background(), 2, true)
+
+	assert.NoError(t, err)
+}
+
+func TestGoToWorkspace_Simulated_CompactsAwayEmptyGap(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	third := sim.AddWorkspace(0, "3\u200b\u200d")
+	sim.AddClient(first)
+	window := sim.AddClient(third)
+	sim.Focus(first)
+
+	err := NewAction(sim, sim).GoToWorkspace(context.Background(), 1, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, 2, sim.Requests, "one snapshot and one batch")
+}
+
+func TestGoToWorkspace_Simulated_CreatesNextWorkspace(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	sim.AddClient(first)
+	sim.Focus(first)
+
+	err := NewAction(sim, sim).GoToWorkspace(context.Background(), 1, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+}
+
+// simulatedMonitorWith sets up a monitor with n local workspaces holding one window each, focused on the first.
+func simulatedMonitorWith(t *testing.T, n int) *simulator.Simulator {
+	t.Helper()
+
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+
+	var first int
+	for i := 0; i < n; i++ {
+		name, err := GetZeroWidthNameFromIndex(0, i)
+		require.NoError(t, err)
+
+		id := sim.AddWorkspace(0, name)
+		sim.AddClient(id)
+		if i == 0 {
+			first = id
+		}
+	}
+
+	sim.Focus(first)
+	return sim
+}
+
+func TestGoToWorkspace_Simulated_KeptLabelIsTheTarget(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1:chat\u200b\u200b")
+	second := sim.AddWorkspace(0, "2:web\u200b\u200c")
+	sim.AddClient(first)
+	sim.AddClient(second)
+	sim.Focus(first)
+
+	// Labelled by an earlier --label, which this command doesn't repeat
+	require.NoError(t, NewAction(sim, sim).GoToWorkspace(context.Background(), 1, true))
+
+	assert.Equal(t, "2:web\u200b\u200c", sim.ActiveWorkspaceName())
+	assert.Equal(t, []string{"1:chat\u200b\u200b", "2:web\u200b\u200c"}, sim.WorkspaceNames(0))
+}
+
+func TestGoToWorkspace_Simulated_MultiDigitIndex(t *testing.T) {
+	sim := simulatedMonitorWith(t, 11)
+
+	require.NoError(t, NewAction(sim, sim).GoToWorkspace(context.Background(), 10, true))
+	assert.Equal(t, "11\u200b\u200c\u200b", sim.ActiveWorkspaceName())
+}
+
+func TestGoToWorkspace_Simulated_BeyondCountCreatesNextWorkspace(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+
+	require.NoError(t, NewAction(sim, sim).GoToWorkspace(context.Background(), 19, true))
+	assert.Equal(t, "4\u200b\u200e", sim.ActiveWorkspaceName())
+	assert.Len(t, sim.WorkspaceNames(0), 4)
+}
+
+func TestGoToWorkspace_Simulated_Labels(t *testing.T) {
+	sim := simulatedMonitorWith(t, 2)
+	labels := NewLabels()
+	require.NoError(t, labels.Set("3=a,b"))
+	action := NewAction(sim, sim).WithLabels(labels)
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 2, true))
+	assert.Equal(t, "3:a\u201ab\u200b\u200d", sim.ActiveWorkspaceName())
+
+	// The labelled workspace sorts by its invisible index and needs no renaming on the way back
+	snap, err := sim.GetSnapshot(context.Background())
+	require.NoError(t, err)
+	sim.AddClient(snap.ActiveWorkspace.ID)
+	sim.Dispatched = nil
+	require.NoError(t, action.GoToWorkspace(context.Background(), 0, true))
+	assert.Equal(t, []DispatchCmd{GoToWorkspaceCmd("1\u200b\u200b")}, sim.Dispatched)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c", "3:a\u201ab\u200b\u200d"}, sim.WorkspaceNames(0))
+}
+
+func TestGoToWorkspace_Simulated_MaxWorkspacesStopsGrowth(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	action := NewAction(sim, sim).WithMaxWorkspaces(3)
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 5, true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+	assert.Len(t, sim.WorkspaceNames(0), 3)
+
+	require.NoError(t, action.CycleWorkspace(context.Background(), "next", true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName())
+}
+
+func TestGoToWorkspace_Simulated_NoCompactBeyondCountCreatesNextWorkspace(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+
+	require.NoError(t, NewAction(sim, sim).GoToWorkspace(context.Background(), 19, false))
+	assert.Equal(t, "4\u200b\u200e", sim.ActiveWorkspaceName())
+	assert.Len(t, sim.WorkspaceNames(0), 4)
+}
+
+func TestGoToWorkspaceOnMonitor_Simulated_CreatesWorkspaceOnOtherMonitor(t *testing.T) {
+	sim, _ := simulatedTwoMonitors(t)
+
+	require.NoError(t, NewAction(sim, sim).GoToWorkspaceOnMonitor(context.Background(), "HDMI-A-1", 1, false, true))
+
+	assert.Equal(t, "2\u200c\u200c", sim.ActiveWorkspaceName())
+	assert.Equal(t, []string{"1\u200c\u200b", "2\u200c\u200c"}, sim.WorkspaceNames(1))
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0))
+}
+
+func TestGoToWorkspaceOnMonitor_Simulated_ShownWorkspaceOnlyFocusesMonitor(t *testing.T) {
+	sim, _ := simulatedTwoMonitors(t)
+
+	require.NoError(t, NewAction(sim, sim).GoToWorkspaceOnMonitor(context.Background(), "#2", 0, false, true))
+
+	assert.Equal(t, []DispatchCmd{FocusMonitorCmd(1)}, sim.Dispatched)
+	assert.Equal(t, "1\u200c\u200b", sim.ActiveWorkspaceName())
+}
+
+func TestGoToWorkspaceOnMonitor_UnknownMonitorError(t *testing.T) {
+	sim, _ := simulatedTwoMonitors(t)
+
+	err := NewAction(sim, sim).GoToWorkspaceOnMonitor(context.Background(), "DP-9", 0, false, true)
 	assert.Error(t, err)
+	assert.Empty(t, sim.Dispatched)
 }
@@ -1,21 +1,23 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestInitWorkspaces_GetMonitorsError(t *testing.T) {
+func TestInitWorkspaces_GetSnapshotError(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	hypr.On("GetMonitors").Return([]MonitorDTO{}, assert.AnError)
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.InitWorkspaces()
+	err := action.InitWorkspaces(context.Background())
 	assert.Error(t, err)
 }
 
@@ -25,31 +27,72 @@ func TestInitWorkspaces_Success(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	hypr.On("GetMonitors").Return([]MonitorDTO{{ID: 0}, {ID: 1}}, nil)
-	// Workspaces for both monitors, correctly named and will not trigger renames
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		{ID: 3, Name: "1\u200c\u200b", MonitorID: 1},
-		{ID: 4, Name: "2\u200c\u200c", MonitorID: 1},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{{ID: 0}, {ID: 1}},
+		// Workspaces for both monitors, correctly named and will not trigger renames
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "1\u200c\u200b", MonitorID: 1},
+			{ID: 4, Name: "2\u200c\u200c", MonitorID: 1},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.InitWorkspaces()
+	err := action.InitWorkspaces(context.Background())
 	assert.NoError(t, err)
 }
 
-func TestInitWorkspaces_CompactionError(t *testing.T) {
+func TestInitWorkspaces_RenamesAllMonitorsInOneBatch(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	hypr.On("GetMonitors").Return([]MonitorDTO{{ID: 0}}, nil)
-	// Cause compaction to fail by returning error on GetWorkspaces
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{{ID: 0}, {ID: 1}},
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1", MonitorID: 0},
+			{ID: 2, Name: "1", MonitorID: 1},
+		},
+	}, nil)
+
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(1, "1\u200b\u200b"),
+		RenameWorkspaceCmd(2, "1\u200c\u200b"),
+	}).Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.InitWorkspaces()
+	err := action.InitWorkspaces(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestInitWorkspaces_DispatchError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors:   []MonitorDTO{{ID: 0}},
+		Workspaces: []WorkspaceDTO{{ID: 1, Name: "ws-1", MonitorID: 0}},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{RenameWorkspaceCmd(1, "1\u200b\u200b")}).Return(assert.AnError)
+
+	action := NewAction(hypr, dispatcher)
+	err := action.InitWorkspaces(context.Background())
 	assert.Error(t, err)
 }
+
+func TestInitWorkspaces_Simulated_RenamesStartupWorkspaces(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	sim.AddMonitor("DP-2")
+
+	err := NewAction(sim, sim).InitWorkspaces(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0))
+	assert.Equal(t, []string{"1\u200c\u200b"}, sim.WorkspaceNames(1))
+	assert.Equal(t, "1\u200b\u200b", sim.ActiveWorkspaceName())
+}
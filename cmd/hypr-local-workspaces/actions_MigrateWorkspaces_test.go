@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMigrationCmds(t *testing.T) {
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "2:web\u200b\u200c", MonitorID: 0},
+		{ID: 3, Name: "1\ufeff\u200c\u200d\ufeff\u200b", MonitorID: 1}, // Slot 12, on whatever monitor it is now
+		{ID: 4, Name: "3\U000E0030\U000E0032", MonitorID: 0},           // Already migrated
+		{ID: 5, Name: "5", MonitorID: 0},
+		{ID: -98, Name: "special:scratch", MonitorID: 0},
+	}
+
+	cmds, err := GetMigrationCmds(workspaces, ZeroWidthCodec, TagCodec)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(1, "1\U000E0030\U000E0030"),
+		RenameWorkspaceCmd(2, "2:web\U000E0030\U000E0031"),
+		RenameWorkspaceCmd(3, "1\U000E007F\U000E0031\U000E0032\U000E007F\U000E0030"),
+	}, cmds)
+}
+
+func TestMigrateWorkspaces_GetWorkspacesError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO(nil), assert.AnError)
+
+	err := NewAction(hypr, dispatcher).MigrateWorkspaces(context.Background(), ZeroWidthCodec, TagCodec)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestMigrateWorkspaces_Simulated_KeepsOrderAndFocus(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	require.NoError(t, NewAction(sim, sim).GoToWorkspace(context.Background(), 1, true))
+
+	action := NewAction(sim, sim).WithCodec(TagCodec)
+	sim.Dispatched = nil
+	require.NoError(t, action.MigrateWorkspaces(context.Background(), ZeroWidthCodec, TagCodec))
+	assert.Len(t, sim.Dispatched, 3, "a rename per workspace")
+
+	assert.Equal(t, []string{
+		"1\U000E0030\U000E0030",
+		"2\U000E0030\U000E0031",
+		"3\U000E0030\U000E0032",
+	}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\U000E0030\U000E0031", sim.ActiveWorkspaceName())
+
+	// Once migrated, the new codec's names need no fixing up
+	sim.Dispatched = nil
+	require.NoError(t, action.GoToWorkspace(context.Background(), 2, true))
+	assert.Equal(t, []DispatchCmd{GoToWorkspaceCmd("3\U000E0030\U000E0032")}, sim.Dispatched)
+}
+
+func TestMigrateWorkspaces_Simulated_LaterCommandsKeepCodec(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	require.NoError(t, NewAction(sim, sim).MigrateWorkspaces(context.Background(), ZeroWidthCodec, TagCodec))
+
+	// Without --codec, compaction and new workspaces stay in the codec the names were migrated to
+	action := NewAction(sim, sim)
+	require.NoError(t, action.GoToWorkspace(context.Background(), 1, true))
+	require.NoError(t, action.MoveToWorkspace(context.Background(), 2, false, true))
+	assert.Equal(t, []string{"1\U000E0030\U000E0030", "2\U000E0030\U000E0031"}, sim.WorkspaceNames(0))
+
+	require.NoError(t, action.GoToWorkspace(context.Background(), 2, true))
+	assert.Equal(t, "3\U000E0030\U000E0032", sim.ActiveWorkspaceName())
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKirtle/hypr-local-workspaces/internal/simulator"
+)
+
+func TestMoveToMonitor_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	err := NewAction(hypr, dispatcher).MoveToMonitor(context.Background(), "next", -1, false, true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestMoveToMonitor_UnknownMonitorError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors:        []MonitorDTO{{ID: 0, Name: "DP-1"}},
+		ActiveWorkspace: WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+	}, nil)
+
+	err := NewAction(hypr, dispatcher).MoveToMonitor(context.Background(), "DP-9", -1, false, true)
+	assert.Error(t, err)
+}
+
+func TestMoveToMonitor_SingleMonitorIsNoOp(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors:        []MonitorDTO{{ID: 0, Name: "DP-1"}},
+		ActiveWorkspace: WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 1},
+		ActiveWindow:    ClientDTO{Address: "0xa"},
+	}, nil)
+
+	err := NewAction(hypr, dispatcher).MoveToMonitor(context.Background(), "next", -1, false, true)
+	assert.NoError(t, err)
+}
+
+func TestMoveToMonitor_ToShownWorkspace(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{
+			{ID: 0, Name: "DP-1"},
+			{ID: 1, Name: "HDMI-A-1", ActiveWorkspace: SimpleWorkspace{ID: 4, Name: "2\u200c\u200c"}},
+		},
+		ActiveWorkspace: WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 2},
+		ActiveWindow:    ClientDTO{Address: "0xa"},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{MoveAddrToWorkspaceCmd("2\u200c\u200c", "0xa")}).Return(nil)
+
+	err := NewAction(hypr, dispatcher).MoveToMonitor(context.Background(), "HDMI-A-1", -1, false, true)
+	assert.NoError(t, err)
+}
+
+func TestMoveToMonitor_AllToNewWorkspace(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}},
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 2},
+			{ID: 4, Name: "1\u200c\u200b", MonitorID: 1, WindowsCount: 1},
+		},
+		Clients: []ClientDTO{
+			{Address: "0xa", Workspace: SimpleWorkspace{ID: 1}},
+			{Address: "0xb", Workspace: SimpleWorkspace{ID: 1}},
+		},
+		ActiveWorkspace: WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 2},
+		ActiveWindow:    ClientDTO{Address: "0xa"},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		FocusMonitorCmd(1),
+		GoToWorkspaceCmd("2\u200c\u200c"),
+		MoveAddrToWorkspaceCmd("2\u200c\u200c", "0xa"),
+		MoveAddrToWorkspaceCmd("2\u200c\u200c", "0xb"),
+	}).Return(nil)
+
+	// Past the last workspace means a new one right after it
+	err := NewAction(hypr, dispatcher).MoveToMonitor(context.Background(), "next", 5, true, true)
+	assert.NoError(t, err)
+}
+
+// simulatedTwoMonitors sets up DP-1 and HDMI-A-1 with one local workspace holding one window each, focused on DP-1.
+func simulatedTwoMonitors(t *testing.T) (*simulator.Simulator, string) {
+	t.Helper()
+
+	sim := newSimulator()
+	var window string
+	var first int
+	for _, name := range []string{"DP-1", "HDMI-A-1"} {
+		monitorID := sim.AddMonitor(name)
+
+		wsName, err := GetZeroWidthNameFromIndex(monitorID, 0)
+		require.NoError(t, err)
+
+		id := sim.AddWorkspace(monitorID, wsName)
+		address := sim.AddClient(id)
+		sim.Focus(id)
+
+		if monitorID == 0 {
+			window, first = address, id
+		}
+	}
+
+	sim.Focus(first)
+	return sim, window
+}
+
+func TestMoveToMonitor_Simulated_ToShownWorkspace(t *testing.T) {
+	sim, window := simulatedTwoMonitors(t)
+
+	require.NoError(t, NewAction(sim, sim).MoveToMonitor(context.Background(), "next", -1, false, true))
+
+	assert.Equal(t, "1\u200c\u200b", sim.ClientWorkspace(window))
+	assert.Equal(t, "1\u200c\u200b", sim.ActiveWorkspaceName(), "focus follows the window")
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0), "the emptied workspace stays on its monitor")
+}
+
+func TestMoveToMonitor_Simulated_CreatesWorkspaceOnTargetMonitor(t *testing.T) {
+	sim, window := simulatedTwoMonitors(t)
+
+	require.NoError(t, NewAction(sim, sim).MoveToMonitor(context.Background(), "HDMI-A-1", 1, false, true))
+
+	assert.Equal(t, "2\u200c\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, []string{"1\u200c\u200b", "2\u200c\u200c"}, sim.WorkspaceNames(1))
+	assert.Equal(t, "2\u200c\u200c", sim.ActiveWorkspaceName())
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMoveToWorkspace_NoActiveWorkspaceError(t *testing.T) {
@@ -12,25 +15,10 @@ func TestMoveToWorkspace_NoActiveWorkspaceError(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	hypr.On("GetActiveWorkspace").Return(WorkspaceDTO{}, assert.AnError)
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(1, false, true)
-	assert.Error(t, err)
-}
-
-func TestMoveToWorkspace_GetWorkspacesError(t *testing.T) {
-	hypr := new(mockHyprctl)
-	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
-	defer dispatcher.AssertExpectations(t)
-
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
-
-	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, false, true)
+	err := action.MoveToWorkspace(context.Background(), 1, false, true)
 	assert.Error(t, err)
 }
 
@@ -41,15 +29,17 @@ func TestMoveToWorkspace_CurrentNotOnListError(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 42, Name: "42\u200b\u200c", MonitorID: 0}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(1, false, true)
+	err := action.MoveToWorkspace(context.Background(), 1, false, true)
 	assert.Error(t, err)
 }
 
@@ -60,16 +50,18 @@ func TestMoveToWorkspace_ReturnsEarlyWhenTargetEqualsCurrent(t *testing.T) {
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 1}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
 	// targetIndex points to current workspace (index 1)
-	err := action.MoveToWorkspace(1, false, true)
+	err := action.MoveToWorkspace(context.Background(), 1, false, true)
 	assert.NoError(t, err)
 }
 
@@ -80,16 +72,18 @@ func TestMoveToWorkspace_ReturnsEarlyWhenSingleWindowMovingForward(t *testing.T)
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 3, Name: "3\u200b\u200d", MonitorID: 0, WindowsCount: 1}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		activeWs,
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			activeWs,
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
 	// targetIndex points beyond current workspace (index 3)
-	err := action.MoveToWorkspace(3, false, true)
+	err := action.MoveToWorkspace(context.Background(), 3, false, true)
 	assert.NoError(t, err)
 }
 
@@ -99,16 +93,18 @@ func TestMoveToWorkspace_ZeroWidthNameError(t *testing.T) {
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 999, WindowsCount: 3}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 999},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 999},
+	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: -1, WindowsCount: 3}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: -1},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: -1},
+		},
 	}, nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, false, true)
+	err := action.MoveToWorkspace(context.Background(), 2, false, true)
 	assert.Error(t, err)
 }
 
@@ -120,26 +116,31 @@ func TestMoveToWorkspace_MoveAllClients_Success_WithCompaction(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 3}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
-	}, nil)
-	hypr.On("GetClientsInWorkspace", activeWs.ID).Return([]ClientDTO{
-		{Address: "0xabc"}, {Address: "0xdef"},
-	}, nil)
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
+		Clients: []ClientDTO{
+			{Address: "0xabc", Workspace: SimpleWorkspace{ID: activeWs.ID}}, {Address: "0xdef", Workspace: SimpleWorkspace{ID: activeWs.ID}},
+		},
+	}, nil).Once()
 
-	// Target index 2 -> target name for monitor 0 index 2
-	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xabc").Return(nil)
-	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xdef").Return(nil)
+	// Emptying the source removes it, so the target shifts down into its slot in the same batch
+	dispatcher.On("Batch", []DispatchCmd{
+		MoveAddrToWorkspaceCmd("3\u200b\u200d", "0xabc"),
+		MoveAddrToWorkspaceCmd("3\u200b\u200d", "0xdef"),
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+	}).Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, true, true)
+	err := action.MoveToWorkspace(context.Background(), 2, true, true)
 	assert.NoError(t, err)
 }
 
-func TestMoveToWorkspace_MoveAllClients_Error_GetClients(t *testing.T) {
+func TestMoveToWorkspace_MoveAllClients_Error_MoveAddr(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
@@ -147,151 +148,296 @@ func TestMoveToWorkspace_MoveAllClients_Error_GetClients(t *testing.T) {
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 3}
 
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
+		Clients: []ClientDTO{
+			{Address: "0xabc", Workspace: SimpleWorkspace{ID: activeWs.ID}}, {Address: "0xdef", Workspace: SimpleWorkspace{ID: activeWs.ID}},
+		},
 	}, nil)
-	hypr.On("GetClientsInWorkspace", activeWs.ID).Return([]ClientDTO{}, assert.AnError)
+
+	dispatcher.On("Batch", mock.Anything).Return(assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, true, true)
+	err := action.MoveToWorkspace(context.Background(), 2, true, true)
 	assert.Error(t, err)
 }
 
-func TestMoveToWorkspace_MoveAllClients_Error_MoveAddr(t *testing.T) {
+func TestMoveToWorkspace_MoveSingleClient_Success_WithCompaction(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 3}
-
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
-	}, nil)
-	hypr.On("GetClientsInWorkspace", activeWs.ID).Return([]ClientDTO{
-		{Address: "0xabc"}, {Address: "0xdef"},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
+		ActiveWindow: ClientDTO{Address: "0xabc"},
 	}, nil)
 
-	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xabc").Return(assert.AnError)
+	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xabc").Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, true, true)
-	assert.Error(t, err)
+	err := action.MoveToWorkspace(context.Background(), 2, false, true)
+	assert.NoError(t, err)
 }
 
-func TestMoveToWorkspace_MoveSingleClient_Success_WithCompaction(t *testing.T) {
+func TestMoveToWorkspace_MoveSingleClient_Error_MoveTo(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 3}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
+		ActiveWindow: ClientDTO{Address: "0xabc"},
 	}, nil)
-	hypr.On("GetActiveWindow").Return(ClientDTO{Address: "0xabc"}, nil)
 
-	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xabc").Return(nil)
+	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xabc").Return(assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, false, true)
-	assert.NoError(t, err)
+	err := action.MoveToWorkspace(context.Background(), 2, false, true)
+	assert.Error(t, err)
 }
 
-func TestMoveToWorkspace_MoveSingleClient_Error_GetActiveWindow(t *testing.T) {
+func TestMoveToWorkspace_NoCompactionWhenCompactFalse(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 3}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 3}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		},
+		ActiveWindow: ClientDTO{Address: "0xabc"},
 	}, nil)
-	hypr.On("GetActiveWindow").Return(ClientDTO{}, assert.AnError)
+
+	dispatcher.On("MoveAddrToWorkspace", "2\u200b\u200c", "0xabc").Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, false, true)
-	assert.Error(t, err)
+	// compact false should skip compaction path
+	err := action.MoveToWorkspace(context.Background(), 1, false, false)
+	assert.NoError(t, err)
 }
 
-func TestMoveToWorkspace_MoveSingleClient_Error_MoveTo(t *testing.T) {
+func TestMoveToWorkspace_CompactionErrorBeforeMove(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 3}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		activeWs,
-		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	activeWs := WorkspaceDTO{ID: 3, Name: "3\u200b\u200d", MonitorID: 0, WindowsCount: 1}
+	// A foreign name cannot be compacted without fixNames
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			activeWs,
+			{ID: 4, Name: "ws-4", MonitorID: 0},
+		},
+		ActiveWindow: ClientDTO{Address: "0xabc"},
 	}, nil)
-	hypr.On("GetActiveWindow").Return(ClientDTO{Address: "0xabc"}, nil)
-
-	dispatcher.On("MoveAddrToWorkspace", "3\u200b\u200d", "0xabc").Return(assert.AnError)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(2, false, true)
+	err := action.MoveToWorkspace(context.Background(), 0, false, true)
 	assert.Error(t, err)
 }
 
-func TestMoveToWorkspace_NoCompactionWhenCompactFalse(t *testing.T) {
+func TestMoveToWorkspace_LastWindowCompactsWithTheMove(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 3}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		activeWs,
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-	}, nil)
-	hypr.On("GetActiveWindow").Return(ClientDTO{Address: "0xabc"}, nil)
-
-	dispatcher.On("MoveAddrToWorkspace", "2\u200b\u200c", "0xabc").Return(nil)
+	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 1}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 1},
+			activeWs,
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0, WindowsCount: 1},
+			{ID: 4, Name: "4\u200b\u200e", MonitorID: 0, WindowsCount: 1},
+		},
+		ActiveWindow: ClientDTO{Address: "0xabc"},
+	}, nil).Once()
+	dispatcher.On("Batch", []DispatchCmd{
+		MoveAddrToWorkspaceCmd("4\u200b\u200e", "0xabc"),
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+		RenameWorkspaceCmd(4, "3\u200b\u200d"),
+	}).Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	// compact false should skip compaction path
-	err := action.MoveToWorkspace(1, false, false)
+	err := action.MoveToWorkspace(context.Background(), 3, false, true)
 	assert.NoError(t, err)
 }
 
-func TestMoveToWorkspace_CompactionErrorAfterMove(t *testing.T) {
+func TestMoveToWorkspace_AllWindowsToNewWorkspaceMovesTheSource(t *testing.T) {
 	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
 	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
-	activeWs := WorkspaceDTO{ID: 3, Name: "3\u200b\u200d", MonitorID: 0, WindowsCount: 1}
-	hypr.On("GetActiveWorkspace").Return(activeWs, nil)
-	// First call (for sorting) succeeds
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
-		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
-		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
-		activeWs,
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 2}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0, WindowsCount: 1},
+		},
+		Clients: []ClientDTO{
+			{Address: "0xabc", Workspace: SimpleWorkspace{ID: activeWs.ID}}, {Address: "0xdef", Workspace: SimpleWorkspace{ID: activeWs.ID}},
+		},
 	}, nil).Once()
-	// Second call (compaction) fails
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError)
-	hypr.On("GetActiveWindow").Return(ClientDTO{Address: "0xabc"}, nil)
 
-	dispatcher.On("MoveAddrToWorkspace", "1\u200b\u200b", "0xabc").Return(nil)
+	// The source ends up in the new workspace's slot, without its windows ever leaving
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(1, TemporaryWorkspaceName(1)),
+		RenameWorkspaceCmd(2, "1\u200b\u200b"),
+		RenameWorkspaceCmd(1, "2\u200b\u200c"),
+	}).Return(nil)
 
 	action := NewAction(hypr, dispatcher)
-	err := action.MoveToWorkspace(0, false, true)
-	assert.Error(t, err)
+	err := action.MoveToWorkspace(context.Background(), 2, true, true)
+	assert.NoError(t, err)
+}
+
+func TestMoveToWorkspace_Simulated_LastWindowLeavesNoGap(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	second := sim.AddWorkspace(0, "2\u200b\u200c")
+	third := sim.AddWorkspace(0, "3\u200b\u200d")
+	moved := sim.AddClient(first)
+	sim.AddClient(second)
+	stays := sim.AddClient(third)
+	sim.Focus(first)
+
+	err := NewAction(sim, sim).MoveToWorkspace(context.Background(), 2, false, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(moved))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(stays))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+	assert.Equal(t, 2, sim.Requests, "a snapshot, then the move and its renames in one batch")
+}
+
+func TestMoveToWorkspace_Simulated_AllWindowsToNewWorkspace(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	second := sim.AddWorkspace(0, "2\u200b\u200c")
+	sim.AddClient(first)
+	a := sim.AddClient(second)
+	b := sim.AddClient(second)
+	sim.Focus(second)
+
+	err := NewAction(sim, sim).MoveToWorkspace(context.Background(), 0, true, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(a))
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(b))
+}
+
+func TestMoveToWorkspace_Simulated_AllWindowsPastTheLastWorkspace(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	second := sim.AddWorkspace(0, "2\u200b\u200c")
+	a := sim.AddClient(first)
+	b := sim.AddClient(first)
+	stays := sim.AddClient(second)
+	sim.Focus(first)
+
+	err := NewAction(sim, sim).MoveToWorkspace(context.Background(), 2, true, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(a))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(b))
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(stays))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+}
+
+func TestMoveToWorkspace_Simulated_KeepsSourceWithRemainingWindows(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	sim.AddClient(first)
+	moved := sim.AddClient(first)
+	sim.Focus(first)
+
+	err := NewAction(sim, sim).MoveToWorkspace(context.Background(), 1, false, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Contains(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.ClientWorkspace(moved))
+}
+
+func TestMoveToWorkspace_Simulated_MaxWorkspaces(t *testing.T) {
+	sim := simulatedMonitorWith(t, 2)
+	action := NewAction(sim, sim).WithMaxWorkspaces(2)
+	snap, err := sim.GetSnapshot(context.Background())
+	require.NoError(t, err)
+	sim.AddClient(snap.ActiveWorkspace.ID)
+
+	// The first workspace keeps a window, so a new third one would exceed the limit
+	require.NoError(t, action.MoveToWorkspace(context.Background(), 2, false, true))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(snap.ActiveWindow.Address))
+	assert.Len(t, sim.WorkspaceNames(0), 2)
+}
+
+func TestMoveToWorkspace_Simulated_ExistingTargetWithOtherCodec(t *testing.T) {
+	sim := simulatedMonitorWith(t, 2)
+	snap, err := sim.GetSnapshot(context.Background())
+	require.NoError(t, err)
+	sim.AddClient(snap.ActiveWorkspace.ID)
+
+	// Not migrated yet: the target must be found by its current name, not the one the new codec would give it
+	err = NewAction(sim, sim).WithCodec(TagCodec).MoveToWorkspace(context.Background(), 1, false, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(snap.ActiveWindow.Address))
+}
+
+func TestMoveToWorkspaceOnMonitor_Simulated_OtherMonitor(t *testing.T) {
+	sim, window := simulatedTwoMonitors(t)
+
+	require.NoError(t, NewAction(sim, sim).MoveToWorkspaceOnMonitor(context.Background(), "HDMI-A-1", 1, false, true))
+
+	assert.Equal(t, "2\u200c\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, []string{"1\u200c\u200b", "2\u200c\u200c"}, sim.WorkspaceNames(1))
+}
+
+func TestMoveToWorkspaceOnMonitor_Simulated_FocusedMonitor(t *testing.T) {
+	sim, window := simulatedTwoMonitors(t)
+
+	// Naming the focused monitor is the same as leaving it out
+	require.NoError(t, NewAction(sim, sim).MoveToWorkspaceOnMonitor(context.Background(), "DP-1", 1, false, true))
+
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(window))
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0))
+	assert.Equal(t, []string{"1\u200c\u200b"}, sim.WorkspaceNames(1))
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveWorkspaceToMonitor_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspaceToMonitor(context.Background(), "next", -1, true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestMoveWorkspaceToMonitor_SingleMonitorIsNoOp(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors:        []MonitorDTO{{ID: 0, Name: "DP-1"}},
+		Workspaces:      []WorkspaceDTO{activeWs},
+		ActiveWorkspace: activeWs,
+	}, nil)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspaceToMonitor(context.Background(), "next", -1, true)
+	assert.NoError(t, err)
+}
+
+func TestMoveWorkspaceToMonitor_RenamesMovesAndCompacts(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}},
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "1\u200c\u200b", MonitorID: 1},
+		},
+		ActiveWorkspace: activeWs,
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(1, "2\u200c\u200c"),
+		RenameWorkspaceCmd(2, "1\u200b\u200b"),
+		MoveWorkspaceToMonitorCmd(1, 1),
+		GoToWorkspaceCmd("2\u200c\u200c"),
+	}).Return(nil)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspaceToMonitor(context.Background(), "HDMI-A-1", -1, true)
+	assert.NoError(t, err)
+}
+
+func TestMoveWorkspaceToMonitor_LastWorkspaceReplacedInTheSameBatch(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}},
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 3, Name: "1\u200c\u200b", MonitorID: 1},
+		},
+		ActiveWorkspace: activeWs,
+	}, nil).Once()
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(1, "2\u200c\u200c"),
+		MoveWorkspaceToMonitorCmd(1, 1),
+		FocusMonitorCmd(0),
+		GoToWorkspaceCmd("1\u200b\u200b"),
+		GoToWorkspaceCmd("2\u200c\u200c"),
+	}).Return(nil)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspaceToMonitor(context.Background(), "HDMI-A-1", -1, true)
+	assert.NoError(t, err)
+}
+
+func TestMoveWorkspaceToMonitor_Simulated_AtIndex(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	first, err := sim.GetActiveWorkspace(context.Background())
+	require.NoError(t, err)
+	window, err := sim.GetActiveWindow(context.Background())
+	require.NoError(t, err)
+
+	sim.AddMonitor("HDMI-A-1")
+	for i := 0; i < 2; i++ {
+		name, err := GetZeroWidthNameFromIndex(1, i)
+		require.NoError(t, err)
+
+		id := sim.AddWorkspace(1, name)
+		sim.AddClient(id)
+		sim.Focus(id)
+	}
+
+	sim.Focus(first.ID)
+
+	require.NoError(t, NewAction(sim, sim).MoveWorkspaceToMonitor(context.Background(), "next", 0, true))
+
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, []string{"1\u200c\u200b", "2\u200c\u200c", "3\u200c\u200d"}, sim.WorkspaceNames(1))
+	assert.Equal(t, "1\u200c\u200b", sim.ClientWorkspace(window.Address))
+	assert.Equal(t, "1\u200c\u200b", sim.ActiveWorkspaceName(), "focus follows the workspace")
+}
+
+func TestMoveWorkspaceToMonitor_Simulated_LastWorkspaceLeavesLocalPlaceholder(t *testing.T) {
+	sim, window := simulatedTwoMonitors(t)
+
+	require.NoError(t, NewAction(sim, sim).MoveWorkspaceToMonitor(context.Background(), "next", -1, true))
+
+	assert.Equal(t, []string{"1\u200c\u200b", "2\u200c\u200c"}, sim.WorkspaceNames(1))
+	assert.Equal(t, "2\u200c\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0), "a local workspace replaces the one Hyprland created in its place")
+	assert.Equal(t, "2\u200c\u200c", sim.ActiveWorkspaceName(), "focus follows the workspace")
+}
+
+func TestMoveWorkspaceToMonitor_Simulated_DaemonModelLeavesLocalPlaceholder(t *testing.T) {
+	sim, window := simulatedTwoMonitors(t)
+
+	// No events reach the model, so it still describes the monitors before the move once the batch is sent
+	require.NoError(t, NewAction(NewModel(sim), sim).MoveWorkspaceToMonitor(context.Background(), "next", -1, true))
+
+	assert.Equal(t, "2\u200c\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(0))
+	assert.Equal(t, 2, sim.Requests, "the model is seeded, then everything lands in one batch")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveWorkspace_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspace(context.Background(), 1, "", true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestMoveWorkspace_LeftOnFirstIsNoOp(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces:      []WorkspaceDTO{activeWs, {ID: 2, Name: "2\u200b\u200c", MonitorID: 0}},
+	}, nil)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspace(context.Background(), -1, "left", true)
+	assert.NoError(t, err)
+}
+
+func TestMoveWorkspace_ToPositionShiftsOthersUp(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 4, Name: "4\u200b\u200e", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+			activeWs,
+		},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(2, TemporaryWorkspaceName(2)),
+		RenameWorkspaceCmd(4, "2\u200b\u200c"),
+		RenameWorkspaceCmd(3, "4\u200b\u200e"),
+		RenameWorkspaceCmd(2, "3\u200b\u200d"),
+	}).Return(nil)
+
+	err := NewAction(hypr, dispatcher).MoveWorkspace(context.Background(), 1, "", true)
+	assert.NoError(t, err)
+}
+
+func TestMoveWorkspace_Simulated_RightAndBack(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+
+	var windows []string
+	var ids []int
+	for i := 0; i < 3; i++ {
+		name, err := GetZeroWidthNameFromIndex(0, i)
+		require.NoError(t, err)
+
+		id := sim.AddWorkspace(0, name)
+		ids = append(ids, id)
+		windows = append(windows, sim.AddClient(id))
+	}
+
+	sim.Focus(ids[0])
+	action := NewAction(sim, sim)
+
+	require.NoError(t, action.MoveWorkspace(context.Background(), -1, "right", true))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(windows[0]))
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(windows[1]))
+
+	require.NoError(t, action.MoveWorkspace(context.Background(), 9, "", true))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName(), "positions past the last mean the last")
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(windows[1]))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(windows[2]))
+
+	require.NoError(t, action.MoveWorkspace(context.Background(), 0, "", true))
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(windows[0]))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(windows[1]))
+	assert.Equal(t, "3\u200b\u200d", sim.ClientWorkspace(windows[2]))
+	assert.Len(t, sim.WorkspaceNames(0), 3)
+}
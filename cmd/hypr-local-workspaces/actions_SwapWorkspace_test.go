@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapWorkspace_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	err := NewAction(hypr, dispatcher).SwapWorkspace(context.Background(), 1, true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestSwapWorkspace_CurrentNotOnListError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: WorkspaceDTO{ID: 42, Name: "42\u200b\u200c", MonitorID: 0},
+		Workspaces:      []WorkspaceDTO{{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}},
+	}, nil)
+
+	err := NewAction(hypr, dispatcher).SwapWorkspace(context.Background(), 0, true)
+	assert.Error(t, err)
+}
+
+func TestSwapWorkspace_SameIndexIsNoOp(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces:      []WorkspaceDTO{{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}, activeWs},
+	}, nil)
+
+	// Past the last workspace means the last one, which is the active one
+	err := NewAction(hypr, dispatcher).SwapWorkspace(context.Background(), 5, true)
+	assert.NoError(t, err)
+}
+
+func TestSwapWorkspace_RenamesThroughTemporaryName(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+			{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+		},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(1, TemporaryWorkspaceName(1)),
+		RenameWorkspaceCmd(3, "1\u200b\u200b"),
+		RenameWorkspaceCmd(1, "3\u200b\u200d"),
+	}).Return(nil)
+
+	err := NewAction(hypr, dispatcher).SwapWorkspace(context.Background(), 2, true)
+	assert.NoError(t, err)
+}
+
+func TestSwapWorkspace_DispatcherError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces:      []WorkspaceDTO{activeWs, {ID: 2, Name: "2\u200b\u200c", MonitorID: 0}},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(1, TemporaryWorkspaceName(1)),
+		RenameWorkspaceCmd(2, "1\u200b\u200b"),
+		RenameWorkspaceCmd(1, "2\u200b\u200c"),
+	}).Return(assert.AnError)
+
+	err := NewAction(hypr, dispatcher).SwapWorkspace(context.Background(), 1, true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestSwapWorkspace_Simulated_KeepsWindowsAndFocus(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+
+	var windows []string
+	var ids []int
+	for i := 0; i < 3; i++ {
+		name, err := GetZeroWidthNameFromIndex(0, i)
+		require.NoError(t, err)
+
+		id := sim.AddWorkspace(0, name)
+		ids = append(ids, id)
+		windows = append(windows, sim.AddClient(id))
+	}
+
+	sim.Focus(ids[0])
+
+	require.NoError(t, NewAction(sim, sim).SwapWorkspace(context.Background(), 2, true))
+
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName(), "focus stays on the swapped content")
+	assert.Equal(t, "3\u200b\u200d", sim.ClientWorkspace(windows[0]))
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(windows[1]))
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(windows[2]))
+	assert.Len(t, sim.WorkspaceNames(0), 3)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// workspacePosition returns the position a workspace name claims: the index+1 of a local name in any codec and
+// for any monitor, or the number a name starts with, like Hyprland's own "3" or a user's "2:code". ok is false for
+// names without a number.
+func workspacePosition(name string) (int, bool) {
+	if _, _, index, err := decodeWorkspaceName(name); err == nil {
+		return index + 1, true
+	}
+
+	position := 0
+	digits := 0
+	for _, r := range name {
+		if r < '0' || r > '9' || digits >= 9 {
+			break
+		}
+
+		position = position*10 + int(r-'0')
+		digits++
+	}
+
+	return position, digits > 0 && position > 0
+}
+
+// GetWorkspacesByPosition returns the workspaces on a monitor in the order the user numbered them: by the
+// position their names claim, then by ID, followed by the ones without a number in ID order. Special workspaces
+// are left out.
+func GetWorkspacesByPosition(workspaces []WorkspaceDTO, monitorID int) []WorkspaceDTO {
+	var onMonitor []WorkspaceDTO
+	for _, ws := range GetWorkspacesOnMonitor(workspaces, monitorID) {
+		if !IsSpecialWorkspace(ws) {
+			onMonitor = append(onMonitor, ws)
+		}
+	}
+
+	sort.Slice(onMonitor, func(i, j int) bool {
+		positionI, okI := workspacePosition(onMonitor[i].Name)
+		positionJ, okJ := workspacePosition(onMonitor[j].Name)
+		if okI != okJ {
+			return okI
+		}
+
+		if okI && positionI != positionJ {
+			return positionI < positionJ
+		}
+
+		return onMonitor[i].ID < onMonitor[j].ID
+	})
+
+	return onMonitor
+}
+
+// GetInitAdoptionCmds returns the renames that bring every workspace into the local scheme of the monitor it is
+// on, keeping the order given by GetWorkspacesByPosition, along with what each rename does. Labels like the "code"
+// of "2:code" are kept the way compaction keeps them, and the renames are ordered by GetRenameCmds, so a workspace
+// never takes a name another one still holds.
+func GetInitAdoptionCmds(workspaces []WorkspaceDTO, monitors []MonitorDTO, namings map[int]Naming) ([]DispatchCmd, []WorkspaceRename, error) {
+	var current []WorkspaceDTO
+	var renames []WorkspaceRename
+	newNames := map[int]string{}
+	for _, mon := range monitors {
+		naming := namingOf(namings, mon.ID)
+		for i, ws := range GetWorkspacesByPosition(workspaces, mon.ID) {
+			newName, err := naming.Rename(ws.Name, i)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			current = append(current, ws)
+			newNames[ws.ID] = newName
+			if ws.Name != newName {
+				renames = append(renames, WorkspaceRename{WorkspaceID: ws.ID, Monitor: mon.Name, From: ws.Name, To: newName})
+			}
+		}
+	}
+
+	return GetRenameCmds(current, newNames), renames, nil
+}
+
+// AdoptWorkspacesOnInit is InitWorkspaces for a session that already has workspaces the user numbered: each
+// one keeps the position its name claims, instead of being renumbered in ID order. It returns the renames applied.
+func (a *Action) AdoptWorkspacesOnInit(ctx context.Context) ([]WorkspaceRename, error) {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	namings, err := a.monitorNamings(snap.Monitors, snap.Workspaces)
+	if err != nil {
+		return nil, err
+	}
+
+	cmds, renames, err := GetInitAdoptionCmds(snap.Workspaces, snap.Monitors, namings)
+	if err != nil {
+		return nil, err
+	}
+
+	return renames, dispatchBatch(ctx, a.dispatcher, cmds)
+}
+
+// PrintRenames writes renames one per line, or as a JSON array.
+func PrintRenames(w io.Writer, renames []WorkspaceRename, asJSON bool) error {
+	if asJSON {
+		if renames == nil {
+			renames = []WorkspaceRename{}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(renames)
+	}
+
+	if len(renames) == 0 {
+		_, err := fmt.Fprintln(w, "no workspaces renamed")
+		return err
+	}
+
+	for _, r := range renames {
+		if _, err := fmt.Fprintf(w, "%s: workspace %d %s -> %s\n", r.Monitor, r.WorkspaceID, quoteIfInvisible(r.From), quoteIfInvisible(r.To)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspacePosition(t *testing.T) {
+	for name, want := range map[string]int{
+		"3":                     3,
+		"12":                    12,
+		"2:code":                2,
+		"4\u200c\u200e":         4,
+		"1\U000E0030\U000E0031": 2,
+	} {
+		position, ok := workspacePosition(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, position, name)
+	}
+
+	for _, name := range []string{"", "web", "0", "code2"} {
+		_, ok := workspacePosition(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestGetWorkspacesByPosition(t *testing.T) {
+	workspaces := []WorkspaceDTO{
+		{ID: 3, Name: "3", MonitorID: 0},
+		{ID: -1337, Name: "web", MonitorID: 0},
+		{ID: 1, Name: "1", MonitorID: 0},
+		{ID: -1338, Name: "2:code", MonitorID: 0},
+		{ID: -98, Name: "special:scratch", MonitorID: 0},
+		{ID: 7, Name: "7", MonitorID: 0},
+		{ID: 5, Name: "5", MonitorID: 1},
+	}
+
+	var ids []int
+	for _, ws := range GetWorkspacesByPosition(workspaces, 0) {
+		ids = append(ids, ws.ID)
+	}
+
+	assert.Equal(t, []int{1, -1338, 3, 7, -1337}, ids)
+}
+
+func TestGetInitAdoptionCmds(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1", MonitorID: 0},
+		{ID: 3, Name: "3", MonitorID: 0},
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0}, // Already local
+		{ID: 5, Name: "5", MonitorID: 1},
+		{ID: 4, Name: "4", MonitorID: 1},
+	}
+
+	cmds, renames, err := GetInitAdoptionCmds(workspaces, monitors, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(1, "1\u200b\u200b"),
+		RenameWorkspaceCmd(3, "3\u200b\u200d"),
+		RenameWorkspaceCmd(4, "1\u200c\u200b"),
+		RenameWorkspaceCmd(5, "2\u200c\u200c"),
+	}, cmds)
+	assert.Equal(t, []WorkspaceRename{
+		{WorkspaceID: 1, Monitor: "DP-1", From: "1", To: "1\u200b\u200b"},
+		{WorkspaceID: 3, Monitor: "DP-1", From: "3", To: "3\u200b\u200d"},
+		{WorkspaceID: 4, Monitor: "HDMI-A-1", From: "4", To: "1\u200c\u200b"},
+		{WorkspaceID: 5, Monitor: "HDMI-A-1", From: "5", To: "2\u200c\u200c"},
+	}, renames)
+}
+
+func TestGetInitAdoptionCmds_KeepsLabelsAndAvoidsTakenNames(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1", MonitorID: 0},
+		{ID: 5, Name: "1\u200b\u200b", MonitorID: 0}, // Holds the name workspace 1 is about to take
+		{ID: 3, Name: "3:code", MonitorID: 0},
+	}
+
+	cmds, renames, err := GetInitAdoptionCmds(workspaces, monitors, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(5, "2\u200b\u200c"),
+		RenameWorkspaceCmd(1, "1\u200b\u200b"),
+		RenameWorkspaceCmd(3, "3:code\u200b\u200d"),
+	}, cmds)
+	assert.Equal(t, []WorkspaceRename{
+		{WorkspaceID: 1, Monitor: "DP-1", From: "1", To: "1\u200b\u200b"},
+		{WorkspaceID: 5, Monitor: "DP-1", From: "1\u200b\u200b", To: "2\u200b\u200c"},
+		{WorkspaceID: 3, Monitor: "DP-1", From: "3:code", To: "3:code\u200b\u200d"},
+	}, renames)
+}
+
+func TestAdoptWorkspacesOnInit_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	_, err := NewAction(hypr, dispatcher).AdoptWorkspacesOnInit(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestAdoptWorkspacesOnInit_Simulated_KeepsNumbering(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+
+	// The user had workspaces 3, 1 and 2, created in that order, plus a named one
+	three := sim.AddWorkspace(0, "3")
+	sim.AddClient(three)
+	window := sim.AddClient(sim.AddWorkspace(0, "1"))
+	sim.AddClient(sim.AddWorkspace(0, "2"))
+	sim.AddClient(sim.AddWorkspace(0, "mail"))
+	sim.Focus(three)
+
+	renames, err := NewAction(sim, sim).AdoptWorkspacesOnInit(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, renames, 4)
+
+	assert.Equal(t, []string{
+		"1\u200b\u200b",
+		"2\u200b\u200c",
+		"3\u200b\u200d",
+		"4\u200b\u200e",
+	}, sim.WorkspaceNames(0))
+	assert.Equal(t, "3\u200b\u200d", sim.ActiveWorkspaceName(), "workspace 3 keeps focus and its position")
+	assert.Equal(t, "1\u200b\u200b", sim.ClientWorkspace(window))
+}
+
+func TestPrintRenames(t *testing.T) {
+	renames := []WorkspaceRename{{WorkspaceID: 3, Monitor: "DP-1", From: "3", To: "3\u200b\u200d"}}
+
+	var out bytes.Buffer
+	require.NoError(t, PrintRenames(&out, renames, false))
+	assert.Equal(t, "DP-1: workspace 3 3 -> \"3\\u200b\\u200d\"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, PrintRenames(&out, nil, false))
+	assert.Equal(t, "no workspaces renamed\n", out.String())
+
+	out.Reset()
+	require.NoError(t, PrintRenames(&out, nil, true))
+	assert.Equal(t, "[]\n", out.String())
+}
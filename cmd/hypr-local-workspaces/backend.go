@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	IPCAuto    = "auto"    // Use the socket when reachable, otherwise fall back to spawning hyprctl
+	IPCSocket  = "socket"  // Talk to Hyprland's request socket directly
+	IPCHyprctl = "hyprctl" // Spawn a hyprctl process per request
+)
+
+// NewClients builds the hyprctl and dispatcher implementations for the requested IPC mode.
+func NewClients(ipc string, timeout time.Duration) (hyprctl, dispatcher, error) {
+	switch ipc {
+	case IPCHyprctl:
+		return NewHyprctlClient(timeout), NewDispatcherClient(timeout), nil
+
+	case IPCSocket:
+		path, err := HyprRequestSocketPath()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil, fmt.Errorf("hyprland socket not available: %w", err)
+		}
+
+		return NewSocketHyprctlClient(path, timeout), NewSocketDispatcherClient(path, timeout), nil
+
+	case IPCAuto, "":
+		path, err := HyprRequestSocketPath()
+		if err == nil {
+			if _, err := os.Stat(path); err == nil {
+				return NewSocketHyprctlClient(path, timeout), NewSocketDispatcherClient(path, timeout), nil
+			}
+		}
+
+		return NewHyprctlClient(timeout), NewDispatcherClient(timeout), nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown ipc mode: %q", ipc)
+	}
+}
+
+// NewRetryingClients builds the clients for the IPC mode in globals, retrying transient failures as configured.
+func NewRetryingClients(globals GlobalFlags) (hyprctl, dispatcher, error) {
+	h, d, err := NewClients(globals.IPC, globals.Timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policy := NewRetryPolicy(globals.Retries, globals.RetryDelay)
+	return NewRetryingHyprctl(h, policy), NewRetryingDispatcher(d, policy), nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TagCodec uses the Unicode tag digits and CANCEL TAG, which are meant to never be rendered. Useful when a font
+// or bar draws some of the zero-width characters.
+var TagCodec Codec = &digitCodec{
+	name:      "tags",
+	digits:    runeRange('\U000E0030', 10), // TAG DIGIT ZERO..NINE
+	delimiter: '\U000E007F',                // CANCEL TAG
+}
+
+// VariationSelectorCodec uses variation selectors, which are stripped by fewer tools than format characters.
+// VS15 and VS16 are left out, since they switch digits between text and emoji presentation.
+var VariationSelectorCodec Codec = &digitCodec{
+	name:      "variation-selectors",
+	digits:    runeRange('\ufe00', 10), // VARIATION SELECTOR-1..10
+	delimiter: '\ufe0a',                // VARIATION SELECTOR-11
+}
+
+// DefaultCodec encodes names unless --codec says otherwise.
+var DefaultCodec = ZeroWidthCodec
+
+// Codecs are all the schemes a name can be written in, in the order they are listed to users.
+var Codecs = []Codec{ZeroWidthCodec, TagCodec, VariationSelectorCodec}
+
+func runeRange(first rune, n int) []rune {
+	runes := make([]rune, n)
+	for i := range runes {
+		runes[i] = first + rune(i)
+	}
+
+	return runes
+}
+
+// CodecByName returns the codec called name.
+func CodecByName(name string) (Codec, error) {
+	for _, codec := range Codecs {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown codec %q, expected one of %s", name, codecNames())
+}
+
+func codecNames() string {
+	names := make([]string, 0, len(Codecs))
+	for _, codec := range Codecs {
+		names = append(names, codec.Name())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// codecOf returns the codec whose alphabet r belongs to, or nil if r is visible.
+func codecOf(r rune) Codec {
+	for _, codec := range Codecs {
+		if codec.Contains(r) {
+			return codec
+		}
+	}
+
+	return nil
+}
+
+func isCodecRune(r rune) bool {
+	return codecOf(r) != nil
+}
+
+// splitWorkspaceName splits name into its visible part (position and label) and its invisible suffix, which is
+// the run of codec runes it ends with. Labels may contain such runes, e.g. the joiners of emoji, but can't end
+// with one. codec is nil when there is no suffix.
+func splitWorkspaceName(name string) (string, string, Codec) {
+	start := len(name)
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(name[:start])
+		if !isCodecRune(r) {
+			break
+		}
+
+		start -= size
+	}
+
+	if start == len(name) {
+		return name, "", nil
+	}
+
+	r, _ := utf8.DecodeRuneInString(name[start:])
+	return name[:start], name[start:], codecOf(r)
+}
+
+// EncodeWorkspaceName builds the name of the workspace at index on monitor slot, with label shown after the
+// position if it isn't empty.
+func EncodeWorkspaceName(codec Codec, slot, index int, label string) (string, error) {
+	suffix, err := codec.Encode(slot, index)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceName := strconv.Itoa(index + 1)
+	if label != "" {
+		workspaceName += ":" + EscapeWorkspaceName(label)
+	}
+
+	return workspaceName + suffix, nil
+}
+
+func (c *digitCodec) Name() string {
+	return c.name
+}
+
+func (c *digitCodec) Contains(r rune) bool {
+	return r == c.delimiter || c.digitValue(r) != -1
+}
+
+func (c *digitCodec) Encode(slot, index int) (string, error) {
+	if slot < 0 {
+		return "", fmt.Errorf("monitorID must be non-negative: %d", slot)
+	}
+
+	if index < 0 {
+		return "", fmt.Errorf("index must be non-negative: %d", index)
+	}
+
+	// Prefix with the monitor slot to ensure uniqueness across monitors
+	var suffix string
+	if slot < len(c.digits) {
+		suffix = string(c.digits[slot])
+	} else {
+		suffix = string(c.delimiter) + c.digitsOf(slot) + string(c.delimiter)
+	}
+
+	// Append one rune for each digit in the index
+	return suffix + c.digitsOf(index), nil
+}
+
+func (c *digitCodec) Decode(suffix string) (int, int, error) {
+	slot, size, err := c.decodeSlot(suffix)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	index := 0
+	digits := 0
+	for _, r := range suffix[size:] {
+		// Guard against overflow, no monitor will ever have a billion workspaces
+		value := c.digitValue(r)
+		if value == -1 || digits >= 9 {
+			return -1, -1, fmt.Errorf("malformed index")
+		}
+
+		index = index*10 + value
+		digits++
+	}
+
+	if digits == 0 {
+		return -1, -1, fmt.Errorf("no index after the monitor segment")
+	}
+
+	return slot, index, nil
+}
+
+// decodeSlot decodes the monitor segment at the start of s and returns the slot and the segment's length in bytes.
+func (c *digitCodec) decodeSlot(s string) (int, int, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if value := c.digitValue(r); value != -1 {
+		return value, size, nil
+	}
+
+	if r != c.delimiter {
+		return -1, 0, fmt.Errorf("missing monitor segment")
+	}
+
+	slot := 0
+	digits := 0
+	for i, r := range s[size:] {
+		if r == c.delimiter {
+			if digits == 0 {
+				return -1, 0, fmt.Errorf("empty monitor segment")
+			}
+
+			return slot, 2*size + i, nil
+		}
+
+		value := c.digitValue(r)
+		if value == -1 || digits >= 9 {
+			return -1, 0, fmt.Errorf("malformed monitor segment")
+		}
+
+		slot = slot*10 + value
+		digits++
+	}
+
+	return -1, 0, fmt.Errorf("unterminated monitor segment")
+}
+
+// digitsOf encodes n (non-negative) as one rune per decimal digit.
+func (c *digitCodec) digitsOf(n int) string {
+	var encoded []rune
+	for _, char := range strconv.Itoa(n) {
+		encoded = append(encoded, c.digits[char-'0'])
+	}
+
+	return string(encoded)
+}
+
+func (c *digitCodec) digitValue(r rune) int {
+	for i, digit := range c.digits {
+		if digit == r {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, codec := range Codecs {
+		for _, slot := range []int{0, 9, 10, 42} {
+			for _, index := range []int{0, 9, 10, 123} {
+				name, err := EncodeWorkspaceName(codec, slot, index, "web")
+				require.NoError(t, err)
+
+				decodedCodec, decodedSlot, decodedIndex, err := decodeWorkspaceName(name)
+				require.NoError(t, err, "%s %q", codec.Name(), name)
+				assert.Equal(t, codec, decodedCodec)
+				assert.Equal(t, slot, decodedSlot)
+				assert.Equal(t, index, decodedIndex)
+
+				assert.True(t, Naming{Codec: codec, Slot: slot}.IsLocal(name))
+			}
+		}
+	}
+}
+
+func TestCodecs_AlphabetsDontOverlap(t *testing.T) {
+	for _, codec := range Codecs {
+		suffix, err := codec.Encode(12, 3456)
+		require.NoError(t, err)
+
+		for _, r := range suffix {
+			assert.Equal(t, codec, codecOf(r), "%s: %U", codec.Name(), r)
+		}
+	}
+}
+
+func TestTagCodec(t *testing.T) {
+	name, err := EncodeWorkspaceName(TagCodec, 1, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, "2\U000E0031\U000E0031", name)
+
+	index, err := GetZeroWidthNameToIndex(name)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	assert.False(t, Naming{Slot: 1}.IsLocal(name), "written by another codec than the default")
+}
+
+func TestDigitCodec_DecodeErrors(t *testing.T) {
+	for _, suffix := range []string{
+		"",
+		"\u200b",                                // No index
+		"\ufeff\u200c\u200b",                    // Unterminated monitor segment
+		"\ufeff\ufeff\u200b",                    // Empty monitor segment
+		"\u200b\u200b\U000E0031",                // Index mixes codecs
+		"\u200b" + strings.Repeat("\u200c", 10), // Index overflows
+	} {
+		_, _, err := ZeroWidthCodec.Decode(suffix)
+		assert.Error(t, err, "%q", suffix)
+	}
+}
+
+func TestCodecByName(t *testing.T) {
+	codec, err := CodecByName("variation-selectors")
+	require.NoError(t, err)
+	assert.Equal(t, VariationSelectorCodec, codec)
+
+	_, err = CodecByName("emoji")
+	assert.ErrorContains(t, err, "zero-width, tags, variation-selectors")
+}
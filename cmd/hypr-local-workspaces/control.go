@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	controlDialTimeout    = 200 * time.Millisecond
+	controlRequestTimeout = 5 * time.Second
+)
+
+type controlRequest struct {
+	Args []string `json:"args"`
+}
+
+type controlResponse struct {
+	Error   string `json:"error,omitempty"`
+	Timeout bool   `json:"timeout,omitempty"` // Error was caused by a deadline, the client exits as if it timed out
+}
+
+// remoteError is an error reported by the daemon, or replayed from a trace. It unwraps to
+// context.DeadlineExceeded when the original request gave up on a deadline, so exit codes match a direct run.
+type remoteError struct {
+	msg     string
+	timeout bool
+}
+
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+func (e *remoteError) Unwrap() error {
+	if e.timeout {
+		return context.DeadlineExceeded
+	}
+
+	return nil
+}
+
+// ControlSocketPath returns the path of the unix socket the daemon accepts forwarded commands on.
+func ControlSocketPath() (string, error) {
+	dir, err := DaemonRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "control.sock"), nil
+}
+
+// ServeControl accepts forwarded CLI invocations on path and runs each one through handle until ctx is
+// cancelled. Each connection carries a single JSON request and receives a single JSON response. The context
+// passed to handle ends when the daemon stops or the request times out.
+func ServeControl(ctx context.Context, path string, handle func(ctx context.Context, args []string) error) error {
+	// We hold the PID file, so any socket left here belongs to a dead daemon
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = listener.Close()
+	})
+	defer stop()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		go serveControlConn(ctx, conn, handle)
+	}
+}
+
+func serveControlConn(ctx context.Context, conn net.Conn, handle func(ctx context.Context, args []string) error) {
+	defer func(conn net.Conn) {
+		_ = conn.Close()
+	}(conn)
+
+	ctx, cancel := context.WithTimeout(ctx, controlRequestTimeout)
+	defer cancel()
+
+	_ = conn.SetDeadline(time.Now().Add(controlRequestTimeout))
+
+	var req controlRequest
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		_ = json.NewEncoder(conn).Encode(controlResponse{Error: "malformed request: " + err.Error()})
+		return
+	}
+
+	var resp controlResponse
+	if err := handle(ctx, req.Args); err != nil {
+		resp.Error = err.Error()
+		resp.Timeout = errors.Is(err, context.DeadlineExceeded)
+	}
+
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// ForwardToDaemon hands a CLI invocation to a running daemon. It reports false when no daemon is listening,
+// in which case the caller should run the command directly. Cancelling ctx abandons the wait for the reply.
+func ForwardToDaemon(ctx context.Context, path string, args []string) (bool, error) {
+	dialer := net.Dialer{Timeout: controlDialTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return false, nil
+	}
+
+	defer func(conn net.Conn) {
+		_ = conn.Close()
+	}(conn)
+
+	deadline := time.Now().Add(controlRequestTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	_ = conn.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := json.NewEncoder(conn).Encode(controlRequest{Args: args}); err != nil {
+		return true, socketError(ctx, err)
+	}
+
+	var resp controlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return true, socketError(ctx, err)
+	}
+
+	if resp.Error != "" {
+		return true, &remoteError{msg: resp.Error, timeout: resp.Timeout}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startControlServer(t *testing.T, handle func(ctx context.Context, args []string) error) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "control.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- ServeControl(ctx, path, handle) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	// Wait for the listener to come up
+	require.Eventually(t, func() bool {
+		forwarded, _ := ForwardToDaemon(context.Background(), path, []string{"ping"})
+		return forwarded
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return path
+}
+
+func TestForwardToDaemon_RoundTrip(t *testing.T) {
+	var received [][]string
+	path := startControlServer(t, func(_ context.Context, args []string) error {
+		received = append(received, args)
+		if args[0] == "fail" {
+			return errors.New("boom")
+		}
+
+		return nil
+	})
+
+	forwarded, err := ForwardToDaemon(context.Background(), path, []string{"goto", "3", "--no-compact"})
+	assert.True(t, forwarded)
+	assert.NoError(t, err)
+
+	forwarded, err = ForwardToDaemon(context.Background(), path, []string{"fail"})
+	assert.True(t, forwarded)
+	assert.EqualError(t, err, "boom")
+
+	assert.Equal(t, []string{"goto", "3", "--no-compact"}, received[len(received)-2])
+}
+
+func TestForwardToDaemon_PreservesRemoteTimeouts(t *testing.T) {
+	path := startControlServer(t, func(ctx context.Context, args []string) error {
+		if args[0] == "ping" {
+			return nil
+		}
+
+		return fmt.Errorf("snapshot: %w", context.DeadlineExceeded)
+	})
+
+	_, err := ForwardToDaemon(context.Background(), path, []string{"goto", "1"})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, ExitTimeout, exitCode(err))
+}
+
+func TestForwardToDaemon_CancelledWhileWaiting(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	path := startControlServer(t, func(ctx context.Context, args []string) error {
+		if args[0] != "ping" {
+			<-release
+		}
+
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	forwarded, err := ForwardToDaemon(ctx, path, []string{"goto", "1"})
+
+	assert.True(t, forwarded)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestForwardToDaemon_NoDaemonFallsBack(t *testing.T) {
+	forwarded, err := ForwardToDaemon(context.Background(), filepath.Join(t.TempDir(), "control.sock"), []string{"goto", "1"})
+
+	assert.False(t, forwarded)
+	assert.NoError(t, err)
+}
+
+func TestDaemon_HandleCommandRunsAction(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	activeWs := WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", MonitorID: 0}
+	hypr.On("GetSnapshot").Return(Snapshot{
+		ActiveWorkspace: activeWs,
+		Workspaces: []WorkspaceDTO{
+			activeWs,
+			{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		},
+	}, nil)
+	dispatcher.On("GoToWorkspace", "2\u200b\u200c").Return(nil)
+
+	d := NewDaemon(NewAction(hypr, dispatcher), nil, nil)
+
+	assert.NoError(t, d.HandleCommand(context.Background(), []string{"goto", "2", "--no-compact"}))
+	assert.Error(t, d.HandleCommand(context.Background(), []string{"goto", "x"}))
+	assert.Error(t, d.HandleCommand(context.Background(), nil))
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const (
+	daemonStopTimeout = 3 * time.Second
+)
+
+// DaemonRuntimeDir returns the directory holding the daemon's runtime files for the running Hyprland instance.
+func DaemonRuntimeDir() (string, error) {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		return "", errors.New("HYPRLAND_INSTANCE_SIGNATURE is not set, is Hyprland running?")
+	}
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = os.TempDir()
+	}
+
+	return filepath.Join(runtimeDir, "hypr-local-workspaces", signature), nil
+}
+
+// DaemonPidPath returns the path of the daemon's PID file.
+func DaemonPidPath() (string, error) {
+	dir, err := DaemonRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "daemon.pid"), nil
+}
+
+func NewDaemon(action *Action, events <-chan Event, errOut io.Writer) *Daemon {
+	return &Daemon{
+		action: action,
+		events: events,
+		errOut: errOut,
+	}
+}
+
+// Run handles events until the channel is closed or ctx is cancelled. Errors from individual events are
+// reported and do not stop the daemon.
+func (d *Daemon) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case ev, ok := <-d.events:
+			if !ok {
+				return nil
+			}
+
+			// The model must see the event before anything reacts to it
+			if d.model != nil {
+				d.model.Apply(ev)
+			}
+
+			d.mu.Lock()
+			err := d.HandleEvent(ctx, ev)
+			d.mu.Unlock()
+
+			if err != nil {
+				_, _ = fmt.Fprintf(d.errOut, "hypr-local-workspaces daemon: %s: %v\n", ev.EventName(), err)
+			}
+		}
+	}
+}
+
+// HandleCommand runs a CLI invocation forwarded over the control socket. The invocation's --deadline applies
+// here, while --timeout is fixed by the flags the daemon itself was started with.
+func (d *Daemon) HandleCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("empty command")
+	}
+
+	cmd, err := parseActionCommand(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+
+	if d.model != nil {
+		// Give the events caused by the previous command a chance to land in the model
+		d.model.WaitSettled()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ctx, cancel := withDeadline(ctx, cmd.Globals.Deadline)
+	defer cancel()
+
+	err = d.action.RunCommand(ctx, cmd)
+	if d.model != nil {
+		d.model.MarkDispatched()
+	}
+
+	return err
+}
+
+// HandleEvent reacts to a single compositor event.
+func (d *Daemon) HandleEvent(ctx context.Context, ev Event) error {
+	switch e := ev.(type) {
+	case ConnectedEvent:
+		// Anything could have happened while we were not listening
+		return AdoptForeignWorkspaces(ctx, d.action)
+
+	case CreateWorkspaceEvent:
+		if IsSpecialWorkspace(WorkspaceDTO{ID: e.ID, Name: e.Name}) {
+			return nil
+		}
+
+		return AdoptWorkspace(ctx, d.action, e.ID)
+
+	case MoveWorkspaceEvent:
+		if IsSpecialWorkspace(WorkspaceDTO{ID: e.ID, Name: e.Name}) {
+			return nil
+		}
+
+		// The name still encodes the monitor it came from
+		return AdoptWorkspace(ctx, d.action, e.ID)
+	}
+
+	return nil
+}
+
+// RunDaemon holds the PID file, adopts foreign workspaces and serves forwarded commands from an
+// event-maintained model until SIGINT or SIGTERM is received.
+func RunDaemon(hyprctl hyprctl, dispatcher dispatcher, slots *MonitorSlots, labels *Labels, codec Codec) error {
+	pidPath, err := DaemonPidPath()
+	if err != nil {
+		return err
+	}
+
+	controlPath, err := ControlSocketPath()
+	if err != nil {
+		return err
+	}
+
+	eventsPath, err := HyprEventSocketPath()
+	if err != nil {
+		return err
+	}
+
+	historyPath, err := HistoryPath()
+	if err != nil {
+		return err
+	}
+
+	release, err := AcquirePidFile(pidPath)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	model := NewModel(hyprctl)
+	events := NewEventListener(eventsPath).Listen(ctx)
+	daemon := NewDaemon(NewAction(model, dispatcher).WithMonitorSlots(slots).WithLabels(labels).WithCodec(codec).WithHistory(NewHistory(historyPath)), events, os.Stderr)
+	daemon.model = model
+
+	go func() {
+		if err := ServeControl(ctx, controlPath, daemon.HandleCommand); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "hypr-local-workspaces daemon: control socket: %v\n", err)
+		}
+	}()
+
+	return daemon.Run(ctx)
+}
+
+// DaemonStatus returns the PID of the running daemon, or an error if none is running.
+func DaemonStatus() (int, error) {
+	path, err := DaemonPidPath()
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := ReadPidFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, errors.New("daemon is not running")
+		}
+
+		return 0, err
+	}
+
+	if !ProcessAlive(pid) {
+		return 0, fmt.Errorf("daemon is not running (stale pid file for pid %d)", pid)
+	}
+
+	return pid, nil
+}
+
+// StopDaemon asks the running daemon to exit and waits for it to do so.
+func StopDaemon() (int, error) {
+	pid, err := DaemonStatus()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return pid, err
+	}
+
+	deadline := time.Now().Add(daemonStopTimeout)
+	for time.Now().Before(deadline) {
+		if !ProcessAlive(pid) {
+			return pid, nil
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return pid, fmt.Errorf("daemon (pid %d) did not exit within %s", pid, daemonStopTimeout)
+}
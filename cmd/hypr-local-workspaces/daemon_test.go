@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAdoptionCmds_AppendsForeignWorkspacesAfterLocalOnes(t *testing.T) {
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "3\u200b\u200d", MonitorID: 0},
+		{ID: 7, Name: "7", MonitorID: 0},
+		{ID: 4, Name: "4", MonitorID: 0},
+		{ID: 5, Name: "1\u200b\u200b", MonitorID: 1}, // Dragged over from monitor 0
+		{ID: -98, Name: "special:scratch", MonitorID: 1},
+	}
+
+	cmds, err := GetAdoptionCmds(workspaces, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(4, "4\u200b\u200e"),
+		RenameWorkspaceCmd(5, "1\u200c\u200b"),
+		RenameWorkspaceCmd(7, "5\u200b\u200f"),
+	}, cmds)
+}
+
+func TestGetAdoptionCmds_FilterLimitsRenames(t *testing.T) {
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 3, Name: "3", MonitorID: 0},
+		{ID: 4, Name: "4", MonitorID: 0},
+	}
+
+	cmds, err := GetAdoptionCmds(workspaces, nil, func(ws WorkspaceDTO) bool { return ws.ID == 4 })
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{RenameWorkspaceCmd(4, "2\u200b\u200c")}, cmds)
+}
+
+func TestGetAdoptionCmds_LabelledWorkspacesAreLocal(t *testing.T) {
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1:mail\u200b\u200b", MonitorID: 0}, // Label from an older configuration, still local
+		{ID: 3, Name: "3", MonitorID: 0},
+	}
+
+	cmds, err := GetAdoptionCmds(workspaces, map[int]Naming{0: {Labels: map[int]string{0: "web", 1: "code"}}}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{RenameWorkspaceCmd(3, "2:code\u200b\u200c")}, cmds)
+}
+
+func TestDaemon_AdoptsCreatedForeignWorkspace(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 4, Name: "4", MonitorID: 0},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{RenameWorkspaceCmd(4, "2\u200b\u200c")}).Return(nil)
+
+	d := NewDaemon(NewAction(hypr, dispatcher), nil, &bytes.Buffer{})
+	err := d.HandleEvent(context.Background(), CreateWorkspaceEvent{ID: 4, Name: "4"})
+
+	assert.NoError(t, err)
+}
+
+func TestDaemon_AdoptsNamedWorkspaceWithNegativeID(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	// Workspaces created by name get negative IDs, they are still regular workspaces
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: -1337, Name: "web", MonitorID: 0},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{RenameWorkspaceCmd(-1337, "2\u200b\u200c")}).Return(nil)
+
+	d := NewDaemon(NewAction(hypr, dispatcher), nil, &bytes.Buffer{})
+	err := d.HandleEvent(context.Background(), CreateWorkspaceEvent{ID: -1337, Name: "web"})
+
+	assert.NoError(t, err)
+}
+
+func TestDaemon_LeavesLocalWorkspacesAlone(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	}, nil)
+
+	d := NewDaemon(NewAction(hypr, dispatcher), nil, &bytes.Buffer{})
+
+	assert.NoError(t, d.HandleEvent(context.Background(), CreateWorkspaceEvent{ID: 2, Name: "2\u200b\u200c"}))
+	assert.NoError(t, d.HandleEvent(context.Background(), CreateWorkspaceEvent{ID: -98, Name: "special:scratch"}))
+	assert.NoError(t, d.HandleEvent(context.Background(), WorkspaceEvent{ID: 2, Name: "2\u200b\u200c"}))
+}
+
+func TestDaemon_ReencodesMovedWorkspace(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 1},
+		{ID: 3, Name: "1\u200c\u200b", MonitorID: 1},
+	}, nil)
+	dispatcher.On("Batch", []DispatchCmd{RenameWorkspaceCmd(2, "2\u200c\u200c")}).Return(nil)
+
+	d := NewDaemon(NewAction(hypr, dispatcher), nil, &bytes.Buffer{})
+	err := d.HandleEvent(context.Background(), MoveWorkspaceEvent{ID: 2, Name: "2\u200b\u200c", Monitor: "DP-2"})
+
+	assert.NoError(t, err)
+}
+
+func TestDaemon_RunSweepsOnConnectAndReportsErrors(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+		{ID: 1, Name: "1", MonitorID: 0},
+	}, nil).Once()
+	dispatcher.On("Batch", []DispatchCmd{RenameWorkspaceCmd(1, "1\u200b\u200b")}).Return(nil).Once()
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{}, assert.AnError).Once()
+
+	events := make(chan Event, 2)
+	events <- ConnectedEvent{}
+	events <- CreateWorkspaceEvent{ID: 2, Name: "2"}
+	close(events)
+
+	errOut := &bytes.Buffer{}
+	d := NewDaemon(NewAction(hypr, dispatcher), events, errOut)
+
+	assert.NoError(t, d.Run(context.Background()))
+	assert.Contains(t, errOut.String(), "createworkspacev2")
+}
+
+func TestAcquirePidFile_SingleInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "daemon.pid")
+
+	release, err := AcquirePidFile(path)
+	require.NoError(t, err)
+
+	pid, err := ReadPidFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+
+	_, err = AcquirePidFile(path)
+	assert.Error(t, err)
+
+	release()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAcquirePidFile_ReplacesStaleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+	// PIDs are capped well below this on Linux, so it can never be alive
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(1<<30)), 0o600))
+
+	release, err := AcquirePidFile(path)
+	require.NoError(t, err)
+	defer release()
+
+	pid, err := ReadPidFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+}
+
+func TestDaemonStatus_NotRunning(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "sig")
+
+	_, err := DaemonStatus()
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeName returns what a local workspace name encodes, in any codec.
+func DecodeName(name string) (DecodedName, error) {
+	codec, slot, index, err := decodeWorkspaceName(name)
+	if err != nil {
+		return DecodedName{}, err
+	}
+
+	visible, _, _ := splitWorkspaceName(name)
+	_, label, _ := strings.Cut(visible, ":")
+
+	return DecodedName{
+		Name:     name,
+		Codec:    codec.Name(),
+		Monitor:  slot,
+		Position: index + 1,
+		Label:    label,
+	}, nil
+}
+
+// String renders d as "mon=1 idx=3", followed by the label if there is one.
+func (d DecodedName) String() string {
+	s := fmt.Sprintf("mon=%d idx=%d", d.Monitor, d.Position)
+	if d.Label != "" {
+		s += " label=" + d.Label
+	}
+
+	return s
+}
+
+func formatDecodedName(d DecodedName, asJSON bool) (string, error) {
+	if !asJSON {
+		return d.String(), nil
+	}
+
+	data, err := json.Marshal(d)
+	return string(data), err
+}
+
+// DecodeNamesInLine replaces every local workspace name in line, e.g. a socket2 event or a line of hyprctl output,
+// with its decoded form. Everything else is kept as is.
+func DecodeNamesInLine(line string, asJSON bool) (string, error) {
+	var out strings.Builder
+
+	done := 0
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if !isCodecRune(r) {
+			i += size
+			continue
+		}
+
+		// The suffix runs up to the first visible rune
+		end := i
+		for end < len(line) {
+			r, size := utf8.DecodeRuneInString(line[end:])
+			if !isCodecRune(r) {
+				break
+			}
+
+			end += size
+		}
+
+		start, ok := nameStart(line[done:i], line[i:end])
+		if !ok {
+			i = end
+			continue
+		}
+
+		d, err := DecodeName(line[done+start : end])
+		if err != nil {
+			i = end
+			continue
+		}
+
+		formatted, err := formatDecodedName(d, asJSON)
+		if err != nil {
+			return "", err
+		}
+
+		out.WriteString(line[done : done+start])
+		out.WriteString(formatted)
+		done, i = end, end
+	}
+
+	out.WriteString(line[done:])
+	return out.String(), nil
+}
+
+// nameStart finds where the visible part of the name ending in suffix starts in before: at the position the suffix
+// encodes, optionally followed by ":" and a label. Labels can't contain the characters that separate fields in
+// events and hyprctl output.
+func nameStart(before, suffix string) (int, bool) {
+	codec := codecOf([]rune(suffix)[0])
+	_, index, err := codec.Decode(suffix)
+	if err != nil {
+		return 0, false
+	}
+
+	position := strconv.Itoa(index + 1)
+	startsName := func(i int) bool {
+		return i == 0 || before[i-1] < '0' || before[i-1] > '9'
+	}
+
+	if start := len(before) - len(position); strings.HasSuffix(before, position) && startsName(start) {
+		return start, true
+	}
+
+	start := strings.LastIndex(before, position+":")
+	if start == -1 || !startsName(start) {
+		return 0, false
+	}
+
+	label := before[start+len(position)+1:]
+	if label == "" || strings.ContainsAny(label, ",>()\t\n") {
+		return 0, false
+	}
+
+	return start, true
+}
+
+// DecodeStream copies r to w line by line, decoding the workspace names in every line. Each line is written as
+// soon as it is read, so it can sit in a pipe behind socat.
+func DecodeStream(r io.Reader, w io.Writer, asJSON bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, err := DecodeNamesInLine(scanner.Text(), asJSON)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// PrintDecodedNames writes the decoded form of every name, one per line.
+func PrintDecodedNames(w io.Writer, names []string, asJSON bool) error {
+	for _, name := range names {
+		d, err := DecodeName(name)
+		if err != nil {
+			return err
+		}
+
+		formatted, err := formatDecodedName(d, asJSON)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(w, formatted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
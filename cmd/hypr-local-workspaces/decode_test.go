@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeName(t *testing.T) {
+	d, err := DecodeName("3:web\u200c\u200d")
+	require.NoError(t, err)
+	assert.Equal(t, DecodedName{Name: "3:web\u200c\u200d", Codec: "zero-width", Monitor: 1, Position: 3, Label: "web"}, d)
+	assert.Equal(t, "mon=1 idx=3 label=web", d.String())
+
+	d, err = DecodeName("12\U000E007F\U000E0031\U000E0032\U000E007F\U000E0031\U000E0031")
+	require.NoError(t, err)
+	assert.Equal(t, "tags", d.Codec)
+	assert.Equal(t, "mon=12 idx=12", d.String())
+
+	d, err = DecodeName("2:\U0001F468\u200d\U0001F4BB\u200b\u200c")
+	require.NoError(t, err)
+	assert.Equal(t, "mon=0 idx=2 label=\U0001F468\u200d\U0001F4BB", d.String(), "the joiner in the label is not part of the suffix")
+
+	_, err = DecodeName("web")
+	assert.Error(t, err)
+}
+
+func TestDecodeNamesInLine(t *testing.T) {
+	for line, want := range map[string]string{
+		"workspace>>2\u200b\u200c":                        "workspace>>mon=0 idx=2",
+		"workspacev2>>-1337,2:web\u200c\u200c":            "workspacev2>>-1337,mon=1 idx=2 label=web",
+		"renameworkspace>>-1337,11\u200b\u200c\u200b":     "renameworkspace>>-1337,mon=0 idx=11",
+		"workspace ID -1337 (1\u200b\u200b) on monitor":   "workspace ID -1337 (mon=0 idx=1) on monitor",
+		"moveworkspace>>1\u200b\u200b,DP-1 3\u200b\u200d": "moveworkspace>>mon=0 idx=1,DP-1 mon=0 idx=3",
+		"workspace>>3":               "workspace>>3",
+		"workspace>>3\u200b\u200b":   "workspace>>3\u200b\u200b", // Encodes position 1, not 3
+		"activewindow>>kitty,\u200b": "activewindow>>kitty,\u200b",
+	} {
+		decoded, err := DecodeNamesInLine(line, false)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded, "%q", line)
+	}
+}
+
+func TestDecodeNamesInLine_JSON(t *testing.T) {
+	decoded, err := DecodeNamesInLine("workspace>>2\u200b\u200c", true)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace>>{\"name\":\"2\u200b\u200c\",\"codec\":\"zero-width\",\"monitor\":0,\"position\":2}", decoded)
+}
+
+func TestDecodeStream(t *testing.T) {
+	in := strings.NewReader("workspace>>2\u200b\u200c\nfocusedmon>>DP-1,1\u200b\u200b\n")
+
+	var out bytes.Buffer
+	require.NoError(t, DecodeStream(in, &out, false))
+	assert.Equal(t, "workspace>>mon=0 idx=2\nfocusedmon>>DP-1,mon=0 idx=1\n", out.String())
+}
+
+func TestPrintDecodedNames(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, PrintDecodedNames(&out, []string{"1\u200b\u200b", "2:code\u200c\u200c"}, false))
+	assert.Equal(t, "mon=0 idx=1\nmon=1 idx=2 label=code\n", out.String())
+
+	assert.Error(t, PrintDecodedNames(&out, []string{"web"}, false))
+}
@@ -1,17 +1,54 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
+)
+
+func NewDispatcherClient(timeout time.Duration) dispatcher {
+	return &dispatcherClient{timeout: timeout}
+}
+
+// The dispatch commands are built by the hypr package, which the simulator shares.
+var (
+	EscapeWorkspaceName       = hypr.EscapeWorkspaceName
+	GoToWorkspaceCmd          = hypr.GoToWorkspaceCmd
+	RenameWorkspaceCmd        = hypr.RenameWorkspaceCmd
+	FocusMonitorCmd           = hypr.FocusMonitorCmd
+	MoveToWorkspaceCmd        = hypr.MoveToWorkspaceCmd
+	MoveAddrToWorkspaceCmd    = hypr.MoveAddrToWorkspaceCmd
+	MoveWorkspaceToMonitorCmd = hypr.MoveWorkspaceToMonitorCmd
 )
 
-func NewDispatcherClient() dispatcher {
-	return &dispatcherClient{}
+// batchRequest joins cmds into a single Hyprland batch body ("dispatch a;dispatch b").
+func batchRequest(cmds []DispatchCmd) string {
+	parts := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		parts = append(parts, "dispatch "+cmd.String())
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// checkBatchReply verifies that every command in a batch was acknowledged with "ok".
+func checkBatchReply(request string, out []byte) error {
+	for _, reply := range strings.Split(string(out), "\n") {
+		reply = strings.TrimSpace(reply)
+		if reply != "" && reply != "ok" {
+			return fmt.Errorf("batch %q: %s", request, reply)
+		}
+	}
+
+	return nil
 }
 
-func hyprDispatch(args ...string) error {
-	allArgs := append([]string{"dispatch"}, args...)
-	_, _, err := RunWith("hyprctl", allArgs, CaptureOutput(), WithTimeout(HyprctlTimeout))
+func hyprDispatch(ctx context.Context, timeout time.Duration, cmd DispatchCmd) error {
+	allArgs := append([]string{"dispatch", cmd.Dispatcher}, cmd.Args...)
+	_, _, err := RunWith("hyprctl", allArgs, CaptureOutput(), WithContext(ctx), WithTimeout(timeout))
 
 	if err != nil {
 		return err
@@ -20,22 +57,45 @@ func hyprDispatch(args ...string) error {
 	return nil
 }
 
-func (d *dispatcherClient) GoToWorkspace(wsName string) error {
-	return hyprDispatch("workspace", fmt.Sprintf("name:%s", wsName))
+func (d *dispatcherClient) GoToWorkspace(ctx context.Context, wsName string) error {
+	return hyprDispatch(ctx, d.timeout, GoToWorkspaceCmd(wsName))
 }
 
-func (d *dispatcherClient) RenameWorkspace(id int, wsNewName string) error {
-	return hyprDispatch("renameworkspace", strconv.Itoa(id), wsNewName)
+func (d *dispatcherClient) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	return hyprDispatch(ctx, d.timeout, RenameWorkspaceCmd(id, wsNewName))
 }
 
-func (d *dispatcherClient) FocusMonitor(monitorId int) error {
-	return hyprDispatch("focusmonitor", strconv.Itoa(monitorId))
+func (d *dispatcherClient) FocusMonitor(ctx context.Context, monitorId int) error {
+	return hyprDispatch(ctx, d.timeout, FocusMonitorCmd(monitorId))
 }
 
-func (d *dispatcherClient) MoveToWorkspace(wsName string) error {
-	return hyprDispatch("movetoworkspace", wsName)
+func (d *dispatcherClient) MoveToWorkspace(ctx context.Context, wsName string) error {
+	return hyprDispatch(ctx, d.timeout, MoveToWorkspaceCmd(wsName))
 }
 
-func (d *dispatcherClient) MoveAddrToWorkspace(wsName, windowAddr string) error {
-	return hyprDispatch("movetoworkspace", fmt.Sprintf("name:%s,address:%s", wsName, windowAddr))
+func (d *dispatcherClient) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	return hyprDispatch(ctx, d.timeout, MoveAddrToWorkspaceCmd(wsName, windowAddr))
+}
+
+func (d *dispatcherClient) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	request := batchRequest(cmds)
+	out, _, err := RunWith("hyprctl", []string{"--batch", request}, CaptureOutput(), WithContext(ctx), WithTimeout(d.timeout))
+	if err != nil {
+		return err
+	}
+
+	return checkBatchReply(request, out)
+}
+
+// dispatchBatch sends cmds as one batch, skipping the round trip entirely when there is nothing to do.
+func dispatchBatch(ctx context.Context, d dispatcher, cmds []DispatchCmd) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	return d.Batch(ctx, cmds)
 }
@@ -1,32 +1,41 @@
 package main
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type mockDispatcher struct {
 	mock.Mock
 }
 
-func (m *mockDispatcher) GoToWorkspace(wsName string) error {
+func (m *mockDispatcher) GoToWorkspace(ctx context.Context, wsName string) error {
 	args := m.Called(wsName)
 	return args.Error(0)
 }
 
-func (m *mockDispatcher) RenameWorkspace(id int, wsNewName string) error {
+func (m *mockDispatcher) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
 	args := m.Called(id, wsNewName)
 	return args.Error(0)
 }
 
-func (m *mockDispatcher) FocusMonitor(monitorId int) error {
+func (m *mockDispatcher) FocusMonitor(ctx context.Context, monitorId int) error {
 	args := m.Called(monitorId)
 	return args.Error(0)
 }
 
-func (m *mockDispatcher) MoveToWorkspace(wsName string) error {
+func (m *mockDispatcher) MoveToWorkspace(ctx context.Context, wsName string) error {
 	args := m.Called(wsName)
 	return args.Error(0)
 }
 
-func (m *mockDispatcher) MoveAddrToWorkspace(wsName, windowAddr string) error {
+func (m *mockDispatcher) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
 	args := m.Called(wsName, windowAddr)
 	return args.Error(0)
 }
+
+func (m *mockDispatcher) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	args := m.Called(cmds)
+	return args.Error(0)
+}
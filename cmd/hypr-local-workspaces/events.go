@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	hyprEventSocket = ".socket2.sock"
+
+	eventReconnectMinDelay = 100 * time.Millisecond
+	eventReconnectMaxDelay = 5 * time.Second
+)
+
+// Event is a single notification received from Hyprland's event socket.
+type Event interface {
+	EventName() string
+}
+
+// ConnectedEvent is emitted every time the listener (re)connects. Events may have been missed while
+// disconnected, so consumers holding state should resynchronize when they see it.
+type ConnectedEvent struct{}
+
+// WorkspaceEvent is emitted when the active workspace changes (workspacev2).
+type WorkspaceEvent struct {
+	ID   int
+	Name string
+}
+
+// CreateWorkspaceEvent is emitted when a workspace is created (createworkspacev2).
+type CreateWorkspaceEvent struct {
+	ID   int
+	Name string
+}
+
+// DestroyWorkspaceEvent is emitted when a workspace is destroyed (destroyworkspacev2).
+type DestroyWorkspaceEvent struct {
+	ID   int
+	Name string
+}
+
+// RenameWorkspaceEvent is emitted when a workspace is renamed (renameworkspace).
+type RenameWorkspaceEvent struct {
+	ID      int
+	NewName string
+}
+
+// MoveWorkspaceEvent is emitted when a workspace is moved to another monitor (moveworkspacev2).
+type MoveWorkspaceEvent struct {
+	ID      int
+	Name    string
+	Monitor string
+}
+
+// OpenWindowEvent is emitted when a window is opened (openwindow).
+type OpenWindowEvent struct {
+	Address       string
+	WorkspaceName string
+	Class         string
+	Title         string
+}
+
+// CloseWindowEvent is emitted when a window is closed (closewindow).
+type CloseWindowEvent struct {
+	Address string
+}
+
+// MoveWindowEvent is emitted when a window is moved to another workspace (movewindowv2).
+type MoveWindowEvent struct {
+	Address       string
+	WorkspaceID   int
+	WorkspaceName string
+}
+
+// ActiveWindowEvent is emitted when the focused window changes (activewindowv2). Address is empty when nothing is focused.
+type ActiveWindowEvent struct {
+	Address string
+}
+
+// MonitorAddedEvent is emitted when a monitor is plugged in (monitoraddedv2).
+type MonitorAddedEvent struct {
+	ID          int
+	Name        string
+	Description string
+}
+
+// MonitorRemovedEvent is emitted when a monitor is unplugged (monitorremoved).
+type MonitorRemovedEvent struct {
+	Name string
+}
+
+// FocusedMonitorEvent is emitted when the focused monitor changes (focusedmon).
+type FocusedMonitorEvent struct {
+	Monitor       string
+	WorkspaceName string
+}
+
+// UnknownEvent carries any event this tool does not model, including the v1 duplicates of modelled v2 events.
+type UnknownEvent struct {
+	Name string
+	Data string
+}
+
+func (ConnectedEvent) EventName() string        { return "connected" }
+func (WorkspaceEvent) EventName() string        { return "workspacev2" }
+func (CreateWorkspaceEvent) EventName() string  { return "createworkspacev2" }
+func (DestroyWorkspaceEvent) EventName() string { return "destroyworkspacev2" }
+func (RenameWorkspaceEvent) EventName() string  { return "renameworkspace" }
+func (MoveWorkspaceEvent) EventName() string    { return "moveworkspacev2" }
+func (OpenWindowEvent) EventName() string       { return "openwindow" }
+func (CloseWindowEvent) EventName() string      { return "closewindow" }
+func (MoveWindowEvent) EventName() string       { return "movewindowv2" }
+func (ActiveWindowEvent) EventName() string     { return "activewindowv2" }
+func (MonitorAddedEvent) EventName() string     { return "monitoraddedv2" }
+func (MonitorRemovedEvent) EventName() string   { return "monitorremoved" }
+func (FocusedMonitorEvent) EventName() string   { return "focusedmon" }
+func (e UnknownEvent) EventName() string        { return e.Name }
+
+// HyprEventSocketPath returns the path of the socket Hyprland broadcasts its events on.
+func HyprEventSocketPath() (string, error) {
+	dir, err := HyprSocketDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, hyprEventSocket), nil
+}
+
+// ParseEvent parses a single "EVENT>>DATA" line from the event socket.
+func ParseEvent(line string) (Event, error) {
+	name, data, found := strings.Cut(line, ">>")
+	if !found {
+		return nil, fmt.Errorf("malformed event line: %q", line)
+	}
+
+	switch name {
+	case "workspacev2":
+		id, wsName, err := parseIDAndName(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		return WorkspaceEvent{ID: id, Name: wsName}, nil
+
+	case "createworkspacev2":
+		id, wsName, err := parseIDAndName(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		return CreateWorkspaceEvent{ID: id, Name: wsName}, nil
+
+	case "destroyworkspacev2":
+		id, wsName, err := parseIDAndName(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		return DestroyWorkspaceEvent{ID: id, Name: wsName}, nil
+
+	case "renameworkspace":
+		id, wsName, err := parseIDAndName(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		return RenameWorkspaceEvent{ID: id, NewName: wsName}, nil
+
+	case "moveworkspacev2":
+		id, rest, err := parseIDAndName(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		// Workspace names may contain commas, monitor names never do
+		sep := strings.LastIndex(rest, ",")
+		if sep == -1 {
+			return nil, fmt.Errorf("parsing %s: missing monitor in %q", name, data)
+		}
+
+		return MoveWorkspaceEvent{ID: id, Name: rest[:sep], Monitor: rest[sep+1:]}, nil
+
+	case "openwindow":
+		fields := strings.SplitN(data, ",", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("parsing %s: expected 4 fields in %q", name, data)
+		}
+
+		return OpenWindowEvent{
+			Address:       normalizeEventAddress(fields[0]),
+			WorkspaceName: fields[1],
+			Class:         fields[2],
+			Title:         fields[3],
+		}, nil
+
+	case "closewindow":
+		return CloseWindowEvent{Address: normalizeEventAddress(data)}, nil
+
+	case "movewindowv2":
+		addr, rest, found := strings.Cut(data, ",")
+		if !found {
+			return nil, fmt.Errorf("parsing %s: expected 3 fields in %q", name, data)
+		}
+
+		id, wsName, err := parseIDAndName(rest)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		return MoveWindowEvent{Address: normalizeEventAddress(addr), WorkspaceID: id, WorkspaceName: wsName}, nil
+
+	case "activewindowv2":
+		if data == "" || data == "," {
+			return ActiveWindowEvent{}, nil
+		}
+
+		return ActiveWindowEvent{Address: normalizeEventAddress(data)}, nil
+
+	case "monitoraddedv2":
+		fields := strings.SplitN(data, ",", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("parsing %s: expected 3 fields in %q", name, data)
+		}
+
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		return MonitorAddedEvent{ID: id, Name: fields[1], Description: fields[2]}, nil
+
+	case "monitorremoved":
+		return MonitorRemovedEvent{Name: data}, nil
+
+	case "focusedmon":
+		monitor, wsName, found := strings.Cut(data, ",")
+		if !found {
+			return nil, fmt.Errorf("parsing %s: expected 2 fields in %q", name, data)
+		}
+
+		return FocusedMonitorEvent{Monitor: monitor, WorkspaceName: wsName}, nil
+
+	default:
+		return UnknownEvent{Name: name, Data: data}, nil
+	}
+}
+
+// parseIDAndName splits "ID,NAME" where NAME may itself contain commas.
+func parseIDAndName(data string) (int, string, error) {
+	idStr, name, found := strings.Cut(data, ",")
+	if !found {
+		return 0, "", fmt.Errorf("expected ID,NAME in %q", data)
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return id, name, nil
+}
+
+// normalizeEventAddress adds the 0x prefix the event socket omits, so addresses match those returned by hyprctl.
+func normalizeEventAddress(addr string) string {
+	if addr == "" || strings.HasPrefix(addr, "0x") {
+		return addr
+	}
+
+	return "0x" + addr
+}
+
+func NewEventListener(path string) *EventListener {
+	return &EventListener{
+		path:          path,
+		minRetryDelay: eventReconnectMinDelay,
+		maxRetryDelay: eventReconnectMaxDelay,
+	}
+}
+
+// Listen connects to the event socket and delivers parsed events until ctx is cancelled, at which point the
+// channel is closed. Lost connections are re-established with exponential backoff; a ConnectedEvent marks
+// each successful (re)connection. Lines that fail to parse are dropped.
+func (l *EventListener) Listen(ctx context.Context) <-chan Event {
+	events := make(chan Event, 64)
+
+	go func() {
+		defer close(events)
+
+		delay := l.minRetryDelay
+		for ctx.Err() == nil {
+			connected, _ := l.readOnce(ctx, events)
+			if connected {
+				delay = l.minRetryDelay
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			delay = min(delay*2, l.maxRetryDelay)
+		}
+	}()
+
+	return events
+}
+
+// readOnce runs a single connection until it drops. It reports whether the connection was established.
+func (l *EventListener) readOnce(ctx context.Context, events chan<- Event) (bool, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", l.path)
+	if err != nil {
+		return false, err
+	}
+
+	// Unblock the scanner when the caller gives up
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+
+	defer stop()
+	defer func(conn net.Conn) {
+		_ = conn.Close()
+	}(conn)
+
+	if !sendEvent(ctx, events, ConnectedEvent{}) {
+		return true, ctx.Err()
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), 1024*1024)
+	for scanner.Scan() {
+		ev, err := ParseEvent(scanner.Text())
+		if err != nil {
+			continue
+		}
+
+		if !sendEvent(ctx, events, ev) {
+			return true, ctx.Err()
+		}
+	}
+
+	return true, scanner.Err()
+}
+
+func sendEvent(ctx context.Context, events chan<- Event, ev Event) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Event
+	}{
+		{"workspacev2>>3,2\u200b\u200c", WorkspaceEvent{ID: 3, Name: "2\u200b\u200c"}},
+		{"createworkspacev2>>4,4", CreateWorkspaceEvent{ID: 4, Name: "4"}},
+		{"destroyworkspacev2>>5,a,b", DestroyWorkspaceEvent{ID: 5, Name: "a,b"}},
+		{"renameworkspace>>6,web", RenameWorkspaceEvent{ID: 6, NewName: "web"}},
+		{"moveworkspacev2>>7,a,b,DP-2", MoveWorkspaceEvent{ID: 7, Name: "a,b", Monitor: "DP-2"}},
+		{"openwindow>>55aa,1\u200b\u200b,kitty,vim a, b", OpenWindowEvent{Address: "0x55aa", WorkspaceName: "1\u200b\u200b", Class: "kitty", Title: "vim a, b"}},
+		{"closewindow>>55aa", CloseWindowEvent{Address: "0x55aa"}},
+		{"movewindowv2>>55aa,8,2\u200b\u200c", MoveWindowEvent{Address: "0x55aa", WorkspaceID: 8, WorkspaceName: "2\u200b\u200c"}},
+		{"activewindowv2>>55aa", ActiveWindowEvent{Address: "0x55aa"}},
+		{"activewindowv2>>", ActiveWindowEvent{}},
+		{"monitoraddedv2>>1,DP-2,Dell Inc. U2720Q", MonitorAddedEvent{ID: 1, Name: "DP-2", Description: "Dell Inc. U2720Q"}},
+		{"monitorremoved>>DP-2", MonitorRemovedEvent{Name: "DP-2"}},
+		{"focusedmon>>DP-2,3\u200c\u200d", FocusedMonitorEvent{Monitor: "DP-2", WorkspaceName: "3\u200c\u200d"}},
+		{"workspace>>2", UnknownEvent{Name: "workspace", Data: "2"}},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			ev, err := ParseEvent(test.line)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ev)
+			assert.Equal(t, test.expected.EventName(), ev.EventName())
+		})
+	}
+}
+
+func TestParseEvent_Errors(t *testing.T) {
+	lines := []string{
+		"no separator",
+		"workspacev2>>x,name",
+		"createworkspacev2>>4",
+		"moveworkspacev2>>7,nomonitor",
+		"openwindow>>55aa,1",
+		"movewindowv2>>55aa",
+		"monitoraddedv2>>x,DP-2,desc",
+		"focusedmon>>DP-2",
+	}
+
+	for i, line := range lines {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParseEvent(line)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEventListener_DeliversEventsAndReconnects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), hyprEventSocket)
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		// First connection drops after one event, the second one stays open
+		for i := 0; ; i++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_, _ = io.WriteString(conn, "garbage\nworkspacev2>>"+strconv.Itoa(i+1)+",ws\n")
+			if i == 0 {
+				_ = conn.Close()
+			}
+		}
+	}()
+
+	l := NewEventListener(path)
+	l.minRetryDelay = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := l.Listen(ctx)
+	var got []Event
+	for ev := range events {
+		got = append(got, ev)
+		if len(got) == 4 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, []Event{
+		ConnectedEvent{},
+		WorkspaceEvent{ID: 1, Name: "ws"},
+		ConnectedEvent{},
+		WorkspaceEvent{ID: 2, Name: "ws"},
+	}, got)
+}
+
+func TestEventListener_ClosesChannelWhenCancelledWhileDisconnected(t *testing.T) {
+	l := NewEventListener(filepath.Join(t.TempDir(), "missing.sock"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := l.Listen(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not stop after cancellation")
+	}
+}
@@ -30,6 +30,7 @@ type CmdOptions struct {
 	DropStderr    bool      // send stderr to /dev/null
 	Detached      bool      // start in new session; stdio redirected to /dev/null
 	Timeout       time.Duration
+	Context       context.Context // nil -> context.Background(); Timeout applies on top
 	Dir           string
 	Env           []string // nil -> inherit; non-nil -> replace
 }
@@ -75,6 +76,11 @@ func WithTimeout(d time.Duration) CmdOpt {
 		o.Timeout = d
 	}
 }
+func WithContext(ctx context.Context) CmdOpt {
+	return func(o *CmdOptions) {
+		o.Context = ctx
+	}
+}
 func WithDir(dir string) CmdOpt {
 	return func(o *CmdOptions) {
 		o.Dir = dir
@@ -115,11 +121,15 @@ func RunWith(bin string, args []string, opts ...CmdOpt) ([]byte, int, error) {
 		stderrBuf bytes.Buffer
 	)
 
-	// Context (timeout)
-	ctx := context.Background()
+	// Context (cancellation + timeout)
+	ctx := cmdOpts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	var cancel context.CancelFunc
 	if cmdOpts.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(context.Background(), cmdOpts.Timeout)
+		ctx, cancel = context.WithTimeout(ctx, cmdOpts.Timeout)
 		defer cancel()
 	}
 
@@ -194,8 +204,8 @@ func RunWith(bin string, args []string, opts ...CmdOpt) ([]byte, int, error) {
 
 	err := cmd.Run()
 
-	// Timeout?
-	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+	// Timeout or cancelled?
+	if ctx.Err() != nil {
 		return stdoutBuf.Bytes(), ExitFailure, ctx.Err()
 	}
 
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+)
+
+func NewHistory(path string) *History {
+	return &History{path: path}
+}
+
+// HistoryPath returns the file persisting the previous local workspace of each monitor. It lives next to the
+// daemon's files, since workspace IDs only mean something to the Hyprland instance that handed them out.
+func HistoryPath() (string, error) {
+	dir, err := DaemonRuntimeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// ReadOnly returns a history that reads from the same file but never writes it.
+func (h *History) ReadOnly() *History {
+	if h == nil {
+		return nil
+	}
+
+	return &History{path: h.path, readOnly: true}
+}
+
+// Previous returns the workspace monitor showed before the last switch, if any was recorded.
+func (h *History) Previous(monitor string) (PreviousWorkspace, bool, error) {
+	if h == nil {
+		return PreviousWorkspace{}, false, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history, err := loadState[PreviousWorkspace](h.path, "workspace history")
+	if err != nil {
+		return PreviousWorkspace{}, false, err
+	}
+
+	previous, ok := history[monitor]
+	return previous, ok, nil
+}
+
+// Record remembers previous as the workspace monitor showed before switching away from it.
+func (h *History) Record(monitor string, previous PreviousWorkspace) error {
+	if h == nil || h.readOnly {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return updateState(h.path, "workspace history", func(history map[string]PreviousWorkspace) bool {
+		history[monitor] = previous
+		return true
+	})
+}
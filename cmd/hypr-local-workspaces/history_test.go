@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_RecordAndPrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	_, ok, err := NewHistory(path).Previous("DP-1")
+	require.NoError(t, err)
+	assert.False(t, ok, "nothing recorded yet")
+
+	require.NoError(t, NewHistory(path).Record("DP-1", PreviousWorkspace{ID: -1338, Index: 2}))
+	require.NoError(t, NewHistory(path).Record("HDMI-A-1", PreviousWorkspace{ID: -1340, Index: 0}))
+
+	// A fresh process reads what the last one recorded
+	previous, ok, err := NewHistory(path).Previous("DP-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, PreviousWorkspace{ID: -1338, Index: 2}, previous)
+}
+
+func TestHistory_ConcurrentRecordsAllLand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	// Separate instances share nothing but the file, like the daemon and a direct command
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, NewHistory(path).Record(fmt.Sprintf("DP-%d", i), PreviousWorkspace{ID: i}))
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		previous, ok, err := NewHistory(path).Previous(fmt.Sprintf("DP-%d", i))
+		require.NoError(t, err)
+		assert.True(t, ok, "DP-%d", i)
+		assert.Equal(t, i, previous.ID)
+	}
+
+	leftovers, err := filepath.Glob(path + ".*.tmp")
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestHistory_ReadOnlyDoesNotWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, NewHistory(path).Record("DP-1", PreviousWorkspace{ID: -1338, Index: 2}))
+
+	history := NewHistory(path).ReadOnly()
+	require.NoError(t, history.Record("DP-1", PreviousWorkspace{ID: -1339, Index: 0}))
+
+	previous, ok, err := history.Previous("DP-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, PreviousWorkspace{ID: -1338, Index: 2}, previous)
+}
+
+func TestHistory_Nil(t *testing.T) {
+	var history *History
+
+	require.NoError(t, history.Record("DP-1", PreviousWorkspace{ID: -1338}))
+	_, ok, err := history.Previous("DP-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestHistory_MalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, _, err := NewHistory(path).Previous("DP-1")
+	assert.ErrorContains(t, err, "decoding workspace history")
+}
@@ -1,37 +1,59 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
 )
 
 const (
 	HyprctlTimeout = 2 * time.Second
 )
 
+// errHyprctlFailed marks a hyprctl process that ran but exited with an error, which is what it does while
+// Hyprland is still starting up.
+var errHyprctlFailed = errors.New("hyprctl failed")
+
 func NewHyprctlClient(timeout time.Duration) hyprctl {
 	return &hyprctlClient{timeout: timeout}
 }
 
-func hyprJson(cmd string) ([]byte, error) {
-	args := []string{"-j", cmd}
-	out, _, err := RunWith("hyprctl", args, CaptureOutput(), WithTimeout(HyprctlTimeout))
+// runHyprctl runs hyprctl with args and returns its output, treating a non-zero exit as an error.
+func runHyprctl(ctx context.Context, timeout time.Duration, args []string) ([]byte, error) {
+	out, code, err := RunWith("hyprctl", args, CaptureOutput(), WithContext(ctx), WithTimeout(timeout))
 	if err != nil {
 		return nil, err
 	}
 
+	if code != ExitSuccess {
+		return nil, fmt.Errorf("%w: %s exited with %d: %s", errHyprctlFailed, strings.Join(args, " "), code, strings.TrimSpace(string(out)))
+	}
+
 	return out, nil
 }
 
-func hyprJsonDecode[T any](cmd string) (T, error) {
-	out, err := hyprJson(cmd)
+func hyprJson(ctx context.Context, timeout time.Duration, cmd string) ([]byte, error) {
+	return runHyprctl(ctx, timeout, []string{"-j", cmd})
+}
+
+func hyprJsonDecode[T any](ctx context.Context, timeout time.Duration, cmd string) (T, error) {
+	out, err := hyprJson(ctx, timeout, cmd)
 	if err != nil {
 		var emptyT T
 		return emptyT, err
 	}
 
+	return decodeJson[T](out)
+}
+
+func decodeJson[T any](out []byte) (T, error) {
 	var result T
-	err = json.Unmarshal(out, &result)
+	err := json.Unmarshal(out, &result)
 	if err != nil {
 		var emptyT T
 		return emptyT, err
@@ -40,47 +62,49 @@ func hyprJsonDecode[T any](cmd string) (T, error) {
 	return result, nil
 }
 
-func (c *hyprctlClient) GetMonitors() ([]MonitorDTO, error) {
-	return hyprJsonDecode[[]MonitorDTO]("monitors")
+func (c *hyprctlClient) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	return hyprJsonDecode[[]MonitorDTO](ctx, c.timeout, "monitors")
 }
 
-func (c *hyprctlClient) GetWorkspaces() ([]WorkspaceDTO, error) {
-	return hyprJsonDecode[[]WorkspaceDTO]("workspaces")
+func (c *hyprctlClient) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
+	return hyprJsonDecode[[]WorkspaceDTO](ctx, c.timeout, "workspaces")
 }
 
-func (c *hyprctlClient) GetClients() ([]ClientDTO, error) {
-	return hyprJsonDecode[[]ClientDTO]("clients")
+func (c *hyprctlClient) GetClients(ctx context.Context) ([]ClientDTO, error) {
+	return hyprJsonDecode[[]ClientDTO](ctx, c.timeout, "clients")
 }
 
-func (c *hyprctlClient) GetClientsInWorkspace(workspaceID int) ([]ClientDTO, error) {
-	clients, err := c.GetClients()
+func (c *hyprctlClient) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
+	clients, err := c.GetClients(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var filtered []ClientDTO
-	for _, client := range clients {
-		if client.Workspace.ID == workspaceID {
-			filtered = append(filtered, client)
-		}
-	}
-
-	return filtered, nil
+	return hypr.FilterClientsByWorkspace(clients, workspaceID), nil
 }
 
-func (c *hyprctlClient) GetActiveWorkspace() (WorkspaceDTO, error) {
-	return hyprJsonDecode[WorkspaceDTO]("activeworkspace")
+func (c *hyprctlClient) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
+	return hyprJsonDecode[WorkspaceDTO](ctx, c.timeout, "activeworkspace")
 }
 
-func (c *hyprctlClient) GetActiveWindow() (ClientDTO, error) {
-	return hyprJsonDecode[ClientDTO]("activewindow")
+func (c *hyprctlClient) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
+	return hyprJsonDecode[ClientDTO](ctx, c.timeout, "activewindow")
 }
 
-func (c *hyprctlClient) GetActiveMonitorID() (int, error) {
-	activeWs, err := c.GetActiveWorkspace()
+func (c *hyprctlClient) GetActiveMonitorID(ctx context.Context) (int, error) {
+	activeWs, err := c.GetActiveWorkspace(ctx)
 	if err != nil {
 		return -1, err
 	}
 
 	return activeWs.MonitorID, nil
 }
+
+func (c *hyprctlClient) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	out, err := runHyprctl(ctx, c.timeout, []string{"--batch", snapshotBatch})
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return decodeSnapshot(out)
+}
@@ -1,48 +1,58 @@
 package main
 
-import "github.com/stretchr/testify/mock"
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type mockHyprctl struct {
 	mock.Mock
 }
 
-func (m *mockHyprctl) GetMonitors() ([]MonitorDTO, error) {
+func (m *mockHyprctl) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
 	args := m.Called()
 	ms, _ := args.Get(0).([]MonitorDTO)
 	return ms, args.Error(1)
 }
 
-func (m *mockHyprctl) GetWorkspaces() ([]WorkspaceDTO, error) {
+func (m *mockHyprctl) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
 	args := m.Called()
 	ws, _ := args.Get(0).([]WorkspaceDTO)
 	return ws, args.Error(1)
 }
 
-func (m *mockHyprctl) GetClients() ([]ClientDTO, error) {
+func (m *mockHyprctl) GetClients(ctx context.Context) ([]ClientDTO, error) {
 	args := m.Called()
 	cs, _ := args.Get(0).([]ClientDTO)
 	return cs, args.Error(1)
 }
 
-func (m *mockHyprctl) GetClientsInWorkspace(workspaceID int) ([]ClientDTO, error) {
+func (m *mockHyprctl) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
 	args := m.Called(workspaceID)
 	cs, _ := args.Get(0).([]ClientDTO)
 	return cs, args.Error(1)
 }
 
-func (m *mockHyprctl) GetActiveWorkspace() (WorkspaceDTO, error) {
+func (m *mockHyprctl) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
 	args := m.Called()
 	ws, _ := args.Get(0).(WorkspaceDTO)
 	return ws, args.Error(1)
 }
 
-func (m *mockHyprctl) GetActiveWindow() (ClientDTO, error) {
+func (m *mockHyprctl) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
 	args := m.Called()
 	c, _ := args.Get(0).(ClientDTO)
 	return c, args.Error(1)
 }
 
-func (m *mockHyprctl) GetActiveMonitorID() (int, error) {
+func (m *mockHyprctl) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	args := m.Called()
+	s, _ := args.Get(0).(Snapshot)
+	return s, args.Error(1)
+}
+
+func (m *mockHyprctl) GetActiveMonitorID(ctx context.Context) (int, error) {
 	args := m.Called()
 	id, _ := args.Get(0).(int)
 	return id, args.Error(1)
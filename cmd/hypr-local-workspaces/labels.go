@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func NewLabels() *Labels {
+	return &Labels{all: map[int]string{}, monitors: map[string]map[int]string{}}
+}
+
+// Set adds a label given as "N=label" or "MONITOR:N=label", so Labels can back a repeatable --label flag.
+func (l *Labels) Set(value string) error {
+	key, label, ok := strings.Cut(value, "=")
+	if !ok || label == "" {
+		return fmt.Errorf("label %q must look like N=label or MONITOR:N=label", value)
+	}
+
+	monitor := ""
+	if i := strings.LastIndex(key, ":"); i != -1 {
+		monitor, key = key[:i], key[i+1:]
+		if monitor == "" {
+			return fmt.Errorf("label %q names no monitor", value)
+		}
+	}
+
+	position, err := strconv.Atoi(key)
+	if err != nil || position < 1 {
+		return fmt.Errorf("label %q must be for a positive workspace position", value)
+	}
+
+	if strings.IndexFunc(label, unicode.IsControl) != -1 {
+		return fmt.Errorf("label %q contains control characters", value)
+	}
+
+	// The invisible suffix is the run of codec runes a name ends with, so one at the end would be taken for it
+	if last, _ := utf8.DecodeLastRuneInString(label); isCodecRune(last) {
+		return fmt.Errorf("label %q ends with an invisible character", value)
+	}
+
+	if monitor == "" {
+		l.all[position] = label
+		return nil
+	}
+
+	if l.monitors[monitor] == nil {
+		l.monitors[monitor] = map[int]string{}
+	}
+
+	l.monitors[monitor][position] = label
+	return nil
+}
+
+// String renders the labels the way Set accepts them, separated by spaces.
+func (l *Labels) String() string {
+	if l == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, position := range sortedPositions(l.all) {
+		parts = append(parts, fmt.Sprintf("%d=%s", position, l.all[position]))
+	}
+
+	monitors := make([]string, 0, len(l.monitors))
+	for monitor := range l.monitors {
+		monitors = append(monitors, monitor)
+	}
+
+	sort.Strings(monitors)
+	for _, monitor := range monitors {
+		for _, position := range sortedPositions(l.monitors[monitor]) {
+			parts = append(parts, fmt.Sprintf("%s:%d=%s", monitor, position, l.monitors[monitor][position]))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func sortedPositions(labels map[int]string) []int {
+	positions := make([]int, 0, len(labels))
+	for position := range labels {
+		positions = append(positions, position)
+	}
+
+	sort.Ints(positions)
+	return positions
+}
+
+// Empty reports whether no label was given.
+func (l *Labels) Empty() bool {
+	return l == nil || (len(l.all) == 0 && len(l.monitors) == 0)
+}
+
+// For returns the labels of the local workspaces on mon by 0-based index, or nil when it has none.
+func (l *Labels) For(mon MonitorDTO) map[int]string {
+	if l.Empty() {
+		return nil
+	}
+
+	labels := map[int]string{}
+	for position, label := range l.all {
+		labels[position-1] = label
+	}
+
+	// A monitor may be listed by its description too, which survives plugging it into another port and wins
+	for _, key := range []string{mon.Name, mon.Description} {
+		for position, label := range l.monitors[key] {
+			labels[position-1] = label
+		}
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	return labels
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels_Set(t *testing.T) {
+	labels := NewLabels()
+	require.NoError(t, labels.Set("1=web"))
+	require.NoError(t, labels.Set("2=a=b"))
+	require.NoError(t, labels.Set("DP-2:1=chat"))
+	require.NoError(t, labels.Set("Dell Inc. DELL U2720Q:3=mail"))
+	require.NoError(t, labels.Set("4=\U0001F468\u200d\U0001F4BB"), "emoji joined by ZERO WIDTH JOINER")
+
+	assert.Equal(t, "1=web 2=a=b 4=\U0001F468\u200d\U0001F4BB DP-2:1=chat Dell Inc. DELL U2720Q:3=mail", labels.String())
+
+	for _, value := range []string{"web", "1=", "0=web", "x=web", ":1=web", "1=web\u200b", "1=web\n"} {
+		assert.Error(t, NewLabels().Set(value), value)
+	}
+}
+
+func TestLabels_For(t *testing.T) {
+	labels := NewLabels()
+	require.NoError(t, labels.Set("1=web"))
+	require.NoError(t, labels.Set("2=code"))
+	require.NoError(t, labels.Set("DP-2:1=chat"))
+	require.NoError(t, labels.Set("DP-2:3=music"))
+	require.NoError(t, labels.Set("Dell U2720Q:3=mail"))
+
+	assert.Equal(t, map[int]string{0: "web", 1: "code"}, labels.For(MonitorDTO{Name: "DP-1"}))
+	assert.Equal(t, map[int]string{0: "chat", 1: "code", 2: "music"}, labels.For(MonitorDTO{Name: "DP-2"}))
+	assert.Equal(t, map[int]string{0: "chat", 1: "code", 2: "mail"}, labels.For(MonitorDTO{Name: "DP-2", Description: "Dell U2720Q"}), "description wins")
+
+	var none *Labels
+	assert.True(t, none.Empty())
+	assert.Nil(t, none.For(MonitorDTO{Name: "DP-1"}))
+}
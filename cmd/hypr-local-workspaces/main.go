@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -16,14 +20,38 @@ func main() {
 	subcmd := args[0]
 	subArgs := args[1:]
 
-	action := NewAction(
-		NewHyprctlClient(2*time.Second),
-		NewDispatcherClient(),
-	)
+	// SIGINT cancels whatever request to Hyprland is in flight instead of killing us mid-batch
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	switch subcmd {
-	case "goto":
-		targetWorkspace, trailing, err := parseGotoArgs(subArgs)
+	case "goto", "move", "cycle", "back", "swap", "move-workspace", "move-to-monitor", "move-workspace-to-monitor":
+		cmd, err := parseActionCommand(subcmd, subArgs)
+		if err != nil {
+			fail(err)
+		}
+
+		ctx, cancel := withDeadline(ctx, cmd.Globals.Deadline)
+		defer cancel()
+
+		// The daemon's requests can't be recorded or held back from here, so --record and --dry-run always run
+		// directly, as do flags that configure clients and slots the daemon already has
+		if cmd.Globals.UseDaemon && cmd.Globals.Record == "" && !cmd.Globals.DryRun && !cmd.Globals.DirectOnly {
+			if path, err := ControlSocketPath(); err == nil {
+				// A running daemon answers from its warm model, otherwise fall through to direct mode
+				if forwarded, err := ForwardToDaemon(ctx, path, args); forwarded {
+					exitOnError(err)
+					return
+				}
+			}
+		}
+
+		exitOnError(runAction(newAction(cmd.Globals), cmd.Globals, func(action *Action) error {
+			return action.RunCommand(ctx, cmd)
+		}))
+
+	case "init":
+		adopt, trailing, err := parseInitArgs(subArgs)
 		if err != nil {
 			fail(err)
 		}
@@ -33,11 +61,31 @@ func main() {
 			fail(err)
 		}
 
-		targetIndex := targetWorkspace - 1
-		_ = action.GoToWorkspace(targetIndex, globals.Compact)
+		ctx, cancel := withDeadline(ctx, globals.Deadline)
+		defer cancel()
+
+		// init usually runs from exec-once, possibly before Hyprland has finished starting
+		hyprctl, dispatcher, err := WaitForHyprland(ctx, globals)
+		exitOnError(err)
+
+		hyprctl, dispatcher = withRecording(globals, hyprctl, dispatcher)
+		action := NewAction(hyprctl, dispatcher).WithMonitorSlots(newMonitorSlots(globals)).WithLabels(globals.Labels).WithCodec(globals.Codec)
+		exitOnError(runAction(action, globals, func(action *Action) error {
+			if !adopt {
+				return action.InitWorkspaces(ctx)
+			}
 
-	case "move":
-		targetWorkspace, all, trailing, err := parseMoveArgs(subArgs)
+			// With --dry-run the planned renames are the output instead
+			renames, err := action.AdoptWorkspacesOnInit(ctx)
+			if err != nil || globals.DryRun {
+				return err
+			}
+
+			return PrintRenames(os.Stdout, renames, globals.JSON)
+		}))
+
+	case "migrate":
+		from, to, trailing, err := parseMigrateArgs(subArgs)
 		if err != nil {
 			fail(err)
 		}
@@ -47,11 +95,15 @@ func main() {
 			fail(err)
 		}
 
-		targetIndex := targetWorkspace - 1
-		_ = action.MoveToWorkspace(targetIndex, all, globals.Compact)
+		ctx, cancel := withDeadline(ctx, globals.Deadline)
+		defer cancel()
+
+		exitOnError(runAction(newAction(globals), globals, func(action *Action) error {
+			return action.MigrateWorkspaces(ctx, from, to)
+		}))
 
-	case "cycle":
-		dir, trailing, err := parseCycleArgs(subArgs)
+	case "decode":
+		names, trailing, err := parseDecodeArgs(subArgs)
 		if err != nil {
 			fail(err)
 		}
@@ -61,10 +113,87 @@ func main() {
 			fail(err)
 		}
 
-		_ = action.CycleWorkspace(dir, globals.Compact)
+		// Without names, filter stdin, e.g. socket2 events piped through socat
+		if len(names) == 0 {
+			exitOnError(DecodeStream(os.Stdin, os.Stdout, globals.JSON))
+			return
+		}
 
-	case "init":
-		_ = action.InitWorkspaces()
+		exitOnError(PrintDecodedNames(os.Stdout, names, globals.JSON))
+
+	case "verify":
+		repair, trailing, err := parseVerifyArgs(subArgs)
+		if err != nil {
+			fail(err)
+		}
+
+		globals, err := parseTrailingGlobalFlags(trailing)
+		if err != nil {
+			fail(err)
+		}
+
+		ctx, cancel := withDeadline(ctx, globals.Deadline)
+		defer cancel()
+
+		// With --dry-run the planned repair is the output instead
+		var problems []NameProblem
+		exitOnError(runAction(newAction(globals), globals, func(action *Action) error {
+			problems, err = action.VerifyWorkspaces(ctx, repair)
+			if err != nil || globals.DryRun {
+				return err
+			}
+
+			return PrintProblems(os.Stdout, problems, globals.JSON)
+		}))
+
+		if len(problems) > 0 && !repair {
+			os.Exit(ExitFailure)
+		}
+
+	case "daemon":
+		daemonCmd, trailing, err := parseDaemonArgs(subArgs)
+		if err != nil {
+			fail(err)
+		}
+
+		globals, err := parseTrailingGlobalFlags(trailing)
+		if err != nil {
+			fail(err)
+		}
+
+		if globals.DryRun {
+			fail(errors.New("--dry-run is not supported by the daemon"))
+		}
+
+		switch daemonCmd {
+		case "status":
+			pid, err := DaemonStatus()
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+				os.Exit(ExitFailure)
+			}
+
+			fmt.Printf("daemon is running (pid %d)\n", pid)
+
+		case "stop":
+			pid, err := StopDaemon()
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+				os.Exit(ExitFailure)
+			}
+
+			fmt.Printf("daemon stopped (pid %d)\n", pid)
+
+		default:
+			hyprctl, dispatcher, err := WaitForHyprland(ctx, globals)
+			exitOnError(err)
+
+			hyprctl, dispatcher = withRecording(globals, hyprctl, dispatcher)
+			if err := RunDaemon(hyprctl, dispatcher, newMonitorSlots(globals), globals.Labels, globals.Codec); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(ExitFailure)
+			}
+		}
 
 	case "help", "-h", "--help", "":
 		printUsage()
@@ -76,14 +205,134 @@ func main() {
 
 // parsing helpers moved to parse.go
 
+func newAction(globals GlobalFlags) *Action {
+	hyprctl, dispatcher, err := NewRetryingClients(globals)
+	if err != nil {
+		fail(err)
+	}
+
+	hyprctl, dispatcher = withRecording(globals, hyprctl, dispatcher)
+	return NewAction(hyprctl, dispatcher).WithMonitorSlots(newMonitorSlots(globals)).WithLabels(globals.Labels).WithCodec(globals.Codec).WithHistory(newHistory())
+}
+
+// newHistory returns the workspace history of the running Hyprland instance, or nil when there is none to tell
+// instances apart by.
+func newHistory() *History {
+	path, err := HistoryPath()
+	if err != nil {
+		return nil
+	}
+
+	return NewHistory(path)
+}
+
+// newMonitorSlots returns the persisted monitor slots for --monitor-identity, or nil when names encode
+// Hyprland's monitor IDs, as they also do when there is no state directory to persist slots in.
+func newMonitorSlots(globals GlobalFlags) *MonitorSlots {
+	if globals.MonitorIdentity == MonitorIdentityID {
+		return nil
+	}
+
+	path, err := MonitorSlotsPath()
+	if err != nil {
+		return nil
+	}
+
+	return NewMonitorSlots(path, globals.MonitorIdentity)
+}
+
+// runAction runs an action, or with --dry-run prints what it would dispatch without touching the compositor.
+func runAction(action *Action, globals GlobalFlags, run func(action *Action) error) error {
+	if !globals.DryRun {
+		return run(action)
+	}
+
+	plan, err := RunPlanned(action, run)
+	if err != nil {
+		return err
+	}
+
+	return PrintPlan(os.Stdout, plan, globals.JSON)
+}
+
+// withRecording wraps the clients so their requests are written to the --record trace, if one was given.
+func withRecording(globals GlobalFlags, hyprctl hyprctl, dispatcher dispatcher) (hyprctl, dispatcher) {
+	if globals.Record == "" {
+		return hyprctl, dispatcher
+	}
+
+	// The trace stays open until the process exits
+	hyprctl, dispatcher, _, err := NewRecordingClients(globals.Record, hyprctl, dispatcher)
+	exitOnError(err)
+
+	return hyprctl, dispatcher
+}
+
+// withDeadline bounds ctx by an overall operation deadline, if one was given.
+func withDeadline(ctx context.Context, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, deadline)
+}
+
+// exitCode maps an action's error to the process exit code.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	default:
+		return ExitFailure
+	}
+}
+
+// exitOnError reports err and exits with the matching code. It does nothing when err is nil.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(exitCode(err))
+}
+
 func printUsage() {
 	_, _ = fmt.Fprintln(os.Stderr, `Usage:
-  hypr-local-workspaces goto  <1..N>         [global flags]
-  hypr-local-workspaces move  <1..N> [--all] [global flags]
-  hypr-local-workspaces cycle <next|prev>    [global flags]
+  hypr-local-workspaces goto  [--back-and-forth] <[monitor:]1..N> [global flags]
+  hypr-local-workspaces move  <[monitor:]1..N> [--all]            [global flags]
+  hypr-local-workspaces cycle <next|prev>                         [global flags]
+  hypr-local-workspaces back                                      [global flags]
+  hypr-local-workspaces swap  <1..N>                              [global flags]
+  hypr-local-workspaces move-workspace <1..N|left|right> [global flags]
+  hypr-local-workspaces move-to-monitor [--all] [--index N] <next|prev|left|right|name> [global flags]
+  hypr-local-workspaces move-workspace-to-monitor [--index N] <next|prev|left|right|name> [global flags]
+  hypr-local-workspaces init [--adopt]       [global flags]
+  hypr-local-workspaces migrate <from> <to>  [global flags]
+  hypr-local-workspaces decode [name...]     [global flags]
+  hypr-local-workspaces verify [--repair]    [global flags]
+  hypr-local-workspaces daemon [run|status|stop] [global flags]
 
 Global flags:
-  --no-compact    Disable compact mode (enabled by default)`)
+  --no-compact           Disable compact mode (enabled by default)
+  --ipc <mode>           How to talk to Hyprland: auto, socket or hyprctl (default auto)
+  --no-daemon            Run directly even if a daemon is listening
+  --timeout <d>          Timeout for each request to Hyprland (default 2s)
+  --deadline <d>         Give up on the whole command after this long (default none)
+  --retries <n>          Retries for requests that failed transiently (default 2)
+  --retry-delay <d>      First retry delay, doubled on every retry (default 100ms)
+  --wait <d>             How long init and the daemon wait for Hyprland to start, 0 to not wait (default 10s)
+  --record <file>        Write every request to Hyprland and its response to a JSON trace (runs without the daemon)
+  --monitor-identity <i> What keeps a monitor's workspaces across hotplugs: description, name or id (default description)
+  --max-workspaces <n>   Never grow a monitor past n local workspaces, 0 for unlimited (default 0)
+  --codec <c>            How new names are encoded: zero-width, tags or variation-selectors (default: as existing names, else zero-width)
+  --label <[mon:]N=text> Show text after position N in its name, e.g. 2=web shows "2:web" (repeatable)
+  --dry-run              Print the dispatches a command would make instead of making them (runs without the daemon)
+  --json                 Print output as JSON`)
 }
 
 func fail(err error) {
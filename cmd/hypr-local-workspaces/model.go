@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
+)
+
+const (
+	modelSettleQuiet = 25 * time.Millisecond
+	modelSettleMax   = 250 * time.Millisecond
+)
+
+// NewModel returns an in-memory model of the compositor that is seeded from source and kept up to date by
+// applying events. It implements the hyprctl interface, so actions can run against it without any queries.
+func NewModel(source hyprctl) *Model {
+	return &Model{
+		source:     source,
+		workspaces: map[int]WorkspaceDTO{},
+		clients:    map[string]ClientDTO{},
+		stale:      true,
+	}
+}
+
+// Apply updates the model with a single event. Events that do not carry enough information to be applied
+// exactly (workspace creation does not say which monitor, monitor hotplug, reconnects, ...) mark the model
+// stale instead, and the next query resynchronizes it from the source.
+func (m *Model) Apply(ev Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastActivity = time.Now()
+	if m.stale {
+		return
+	}
+
+	switch e := ev.(type) {
+	case WorkspaceEvent:
+		ws, ok := m.workspaces[e.ID]
+		mon := m.monitorIndexByName(m.focusedMonitor)
+		if !ok || mon == -1 {
+			m.stale = true
+			return
+		}
+
+		m.monitors[mon].ActiveWorkspace = SimpleWorkspace{ID: ws.ID, Name: ws.Name}
+
+	case DestroyWorkspaceEvent:
+		delete(m.workspaces, e.ID)
+
+	case RenameWorkspaceEvent:
+		ws, ok := m.workspaces[e.ID]
+		if !ok {
+			m.stale = true
+			return
+		}
+
+		ws.Name = e.NewName
+		m.workspaces[e.ID] = ws
+
+		for i := range m.monitors {
+			if m.monitors[i].ActiveWorkspace.ID == e.ID {
+				m.monitors[i].ActiveWorkspace.Name = e.NewName
+			}
+		}
+
+		for addr, client := range m.clients {
+			if client.Workspace.ID == e.ID {
+				client.Workspace.Name = e.NewName
+				m.clients[addr] = client
+			}
+		}
+
+	case MoveWorkspaceEvent:
+		ws, ok := m.workspaces[e.ID]
+		mon := m.monitorIndexByName(e.Monitor)
+		if !ok || mon == -1 {
+			m.stale = true
+			return
+		}
+
+		ws.Monitor = e.Monitor
+		ws.MonitorID = m.monitors[mon].ID
+		m.workspaces[e.ID] = ws
+
+	case OpenWindowEvent:
+		ws, ok := m.workspaceByName(e.WorkspaceName)
+		if !ok {
+			m.stale = true
+			return
+		}
+
+		m.clients[e.Address] = ClientDTO{
+			Address:   e.Address,
+			Monitor:   ws.MonitorID,
+			Workspace: SimpleWorkspace{ID: ws.ID, Name: ws.Name},
+		}
+
+	case CloseWindowEvent:
+		delete(m.clients, e.Address)
+		if m.activeWindow == e.Address {
+			m.activeWindow = ""
+		}
+
+	case MoveWindowEvent:
+		client, clientOk := m.clients[e.Address]
+		ws, wsOk := m.workspaces[e.WorkspaceID]
+		if !clientOk || !wsOk {
+			m.stale = true
+			return
+		}
+
+		client.Monitor = ws.MonitorID
+		client.Workspace = SimpleWorkspace{ID: ws.ID, Name: ws.Name}
+		m.clients[e.Address] = client
+
+	case ActiveWindowEvent:
+		m.activeWindow = e.Address
+
+	case FocusedMonitorEvent:
+		mon := m.monitorIndexByName(e.Monitor)
+		ws, ok := m.workspaceByName(e.WorkspaceName)
+		if mon == -1 || !ok {
+			m.stale = true
+			return
+		}
+
+		m.focusedMonitor = e.Monitor
+		for i := range m.monitors {
+			m.monitors[i].Focused = i == mon
+		}
+
+		m.monitors[mon].ActiveWorkspace = SimpleWorkspace{ID: ws.ID, Name: ws.Name}
+
+	case ConnectedEvent, CreateWorkspaceEvent, MonitorAddedEvent, MonitorRemovedEvent:
+		m.stale = true
+	}
+}
+
+// Invalidate forces the next query to resynchronize from the source.
+func (m *Model) Invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stale = true
+}
+
+// MarkDispatched records that the model's owner just changed the compositor, so the next WaitSettled call
+// gives the resulting events a chance to arrive.
+func (m *Model) MarkDispatched() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dispatched = true
+	m.lastActivity = time.Now()
+}
+
+// WaitSettled blocks until no event has been applied for a short quiet period after the last dispatch,
+// bounded by a maximum wait. It returns immediately if nothing was dispatched since the last call.
+func (m *Model) WaitSettled() {
+	deadline := time.Now().Add(modelSettleMax)
+	for {
+		m.mu.Lock()
+		dispatched, idle := m.dispatched, time.Since(m.lastActivity)
+		if !dispatched || idle >= modelSettleQuiet || time.Now().After(deadline) {
+			m.dispatched = false
+			m.mu.Unlock()
+			return
+		}
+		m.mu.Unlock()
+
+		time.Sleep(modelSettleQuiet - idle)
+	}
+}
+
+// refreshLocked resynchronizes the model from the source if needed. Callers must hold m.mu.
+func (m *Model) refreshLocked(ctx context.Context) error {
+	if !m.stale {
+		return nil
+	}
+
+	snap, err := m.source.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.monitors = snap.Monitors
+	m.focusedMonitor = ""
+	for _, mon := range snap.Monitors {
+		if mon.Focused {
+			m.focusedMonitor = mon.Name
+		}
+	}
+
+	m.workspaces = make(map[int]WorkspaceDTO, len(snap.Workspaces))
+	for _, ws := range snap.Workspaces {
+		m.workspaces[ws.ID] = ws
+	}
+
+	m.clients = make(map[string]ClientDTO, len(snap.Clients))
+	for _, client := range snap.Clients {
+		m.clients[client.Address] = client
+	}
+
+	m.activeWindow = snap.ActiveWindow.Address
+	m.stale = false
+
+	return nil
+}
+
+func (m *Model) monitorIndexByName(name string) int {
+	for i, mon := range m.monitors {
+		if mon.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (m *Model) workspaceByName(name string) (WorkspaceDTO, bool) {
+	for _, ws := range m.workspaces {
+		if ws.Name == name {
+			return ws, true
+		}
+	}
+
+	return WorkspaceDTO{}, false
+}
+
+// workspaceLocked returns a workspace with its window count derived from the tracked clients.
+func (m *Model) workspaceLocked(ws WorkspaceDTO) WorkspaceDTO {
+	ws.WindowsCount = 0
+	for _, client := range m.clients {
+		if client.Workspace.ID == ws.ID {
+			ws.WindowsCount++
+		}
+	}
+
+	return ws
+}
+
+// workspacesLocked returns the tracked workspaces ordered by ID. Callers must hold m.mu.
+func (m *Model) workspacesLocked() []WorkspaceDTO {
+	workspaces := make([]WorkspaceDTO, 0, len(m.workspaces))
+	for _, ws := range m.workspaces {
+		workspaces = append(workspaces, m.workspaceLocked(ws))
+	}
+
+	// Map iteration order is random, hyprctl reports workspaces by ID
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].ID < workspaces[j].ID
+	})
+
+	return workspaces
+}
+
+// clientsLocked returns the tracked clients ordered by address. Callers must hold m.mu.
+func (m *Model) clientsLocked() []ClientDTO {
+	clients := make([]ClientDTO, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Address < clients[j].Address
+	})
+
+	return clients
+}
+
+// activeWorkspaceLocked returns the active workspace of the focused monitor. Callers must hold m.mu.
+func (m *Model) activeWorkspaceLocked() WorkspaceDTO {
+	mon := m.monitorIndexByName(m.focusedMonitor)
+	if mon == -1 {
+		return WorkspaceDTO{}
+	}
+
+	ws, ok := m.workspaces[m.monitors[mon].ActiveWorkspace.ID]
+	if !ok {
+		return WorkspaceDTO{}
+	}
+
+	return m.workspaceLocked(ws)
+}
+
+func (m *Model) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refreshLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	return append([]MonitorDTO(nil), m.monitors...), nil
+}
+
+func (m *Model) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refreshLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.workspacesLocked(), nil
+}
+
+func (m *Model) GetClients(ctx context.Context) ([]ClientDTO, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refreshLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	return m.clientsLocked(), nil
+}
+
+func (m *Model) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
+	clients, err := m.GetClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return hypr.FilterClientsByWorkspace(clients, workspaceID), nil
+}
+
+func (m *Model) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refreshLocked(ctx); err != nil {
+		return WorkspaceDTO{}, err
+	}
+
+	return m.activeWorkspaceLocked(), nil
+}
+
+func (m *Model) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refreshLocked(ctx); err != nil {
+		return ClientDTO{}, err
+	}
+
+	return m.clients[m.activeWindow], nil
+}
+
+func (m *Model) GetActiveMonitorID(ctx context.Context) (int, error) {
+	activeWs, err := m.GetActiveWorkspace(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	return activeWs.MonitorID, nil
+}
+
+func (m *Model) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.refreshLocked(ctx); err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{
+		Monitors:        append([]MonitorDTO(nil), m.monitors...),
+		Workspaces:      m.workspacesLocked(),
+		Clients:         m.clientsLocked(),
+		ActiveWorkspace: m.activeWorkspaceLocked(),
+		ActiveWindow:    m.clients[m.activeWindow],
+	}, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seededModel(t *testing.T) (*Model, *mockHyprctl) {
+	t.Helper()
+
+	hypr := new(mockHyprctl)
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors: []MonitorDTO{
+			{ID: 0, Name: "DP-1", Focused: true, ActiveWorkspace: SimpleWorkspace{ID: 1, Name: "1\u200b\u200b"}},
+			{ID: 1, Name: "DP-2", ActiveWorkspace: SimpleWorkspace{ID: 3, Name: "1\u200c\u200b"}},
+		},
+		Workspaces: []WorkspaceDTO{
+			{ID: 1, Name: "1\u200b\u200b", Monitor: "DP-1", MonitorID: 0, WindowsCount: 1},
+			{ID: 2, Name: "2\u200b\u200c", Monitor: "DP-1", MonitorID: 0, WindowsCount: 1},
+			{ID: 3, Name: "1\u200c\u200b", Monitor: "DP-2", MonitorID: 1},
+		},
+		Clients: []ClientDTO{
+			{Address: "0xa", Monitor: 0, Workspace: SimpleWorkspace{ID: 1, Name: "1\u200b\u200b"}},
+			{Address: "0xb", Monitor: 0, Workspace: SimpleWorkspace{ID: 2, Name: "2\u200b\u200c"}},
+		},
+		ActiveWindow: ClientDTO{Address: "0xa"},
+	}, nil).Once()
+
+	return NewModel(hypr), hypr
+}
+
+func TestModel_SeedsFromSourceOnce(t *testing.T) {
+	model, hypr := seededModel(t)
+	defer hypr.AssertExpectations(t)
+
+	activeWs, err := model.GetActiveWorkspace(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, WorkspaceDTO{ID: 1, Name: "1\u200b\u200b", Monitor: "DP-1", MonitorID: 0, WindowsCount: 1}, activeWs)
+
+	// Served from memory, the mock would fail on a second round of queries
+	workspaces, err := model.GetWorkspaces(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, workspaces, 3)
+
+	window, err := model.GetActiveWindow(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0xa", window.Address)
+}
+
+func TestModel_AppliesEvents(t *testing.T) {
+	model, hypr := seededModel(t)
+	defer hypr.AssertExpectations(t)
+
+	_, err := model.GetMonitors(context.Background())
+	require.NoError(t, err)
+
+	model.Apply(RenameWorkspaceEvent{ID: 2, NewName: "3\u200b\u200d"})
+	model.Apply(MoveWindowEvent{Address: "0xa", WorkspaceID: 2, WorkspaceName: "3\u200b\u200d"})
+	model.Apply(WorkspaceEvent{ID: 2, Name: "3\u200b\u200d"})
+	model.Apply(OpenWindowEvent{Address: "0xc", WorkspaceName: "1\u200c\u200b", Class: "kitty"})
+	model.Apply(DestroyWorkspaceEvent{ID: 1, Name: "1\u200b\u200b"})
+	model.Apply(ActiveWindowEvent{Address: "0xc"})
+
+	workspaces, err := model.GetWorkspaces(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []WorkspaceDTO{
+		{ID: 2, Name: "3\u200b\u200d", Monitor: "DP-1", MonitorID: 0, WindowsCount: 2},
+		{ID: 3, Name: "1\u200c\u200b", Monitor: "DP-2", MonitorID: 1, WindowsCount: 1},
+	}, workspaces)
+
+	activeWs, err := model.GetActiveWorkspace(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, activeWs.ID)
+
+	clients, err := model.GetClientsInWorkspace(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Len(t, clients, 2)
+
+	window, err := model.GetActiveWindow(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ClientDTO{Address: "0xc", Monitor: 1, Workspace: SimpleWorkspace{ID: 3, Name: "1\u200c\u200b"}}, window)
+
+	model.Apply(FocusedMonitorEvent{Monitor: "DP-2", WorkspaceName: "1\u200c\u200b"})
+	monitorID, err := model.GetActiveMonitorID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, monitorID)
+
+	model.Apply(MoveWorkspaceEvent{ID: 2, Name: "3\u200b\u200d", Monitor: "DP-2"})
+	workspaces, err = model.GetWorkspaces(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, workspaces[0].MonitorID)
+}
+
+func TestModel_IncompleteEventsResync(t *testing.T) {
+	model, hypr := seededModel(t)
+	defer hypr.AssertExpectations(t)
+
+	_, err := model.GetWorkspaces(context.Background())
+	require.NoError(t, err)
+
+	// Creation does not say which monitor the workspace is on, so the model asks again
+	model.Apply(CreateWorkspaceEvent{ID: 4, Name: "4"})
+	hypr.On("GetSnapshot").Return(Snapshot{
+		Monitors:   []MonitorDTO{{ID: 0, Name: "DP-1", Focused: true}},
+		Workspaces: []WorkspaceDTO{{ID: 4, Name: "4", MonitorID: 0}},
+	}, nil).Once()
+
+	workspaces, err := model.GetWorkspaces(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []WorkspaceDTO{{ID: 4, Name: "4", MonitorID: 0}}, workspaces)
+}
+
+func TestModel_RefreshErrorsPropagate(t *testing.T) {
+	hypr := new(mockHyprctl)
+	defer hypr.AssertExpectations(t)
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	model := NewModel(hypr)
+	_, err := model.GetActiveWorkspace(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestModel_GetSnapshotIsConsistentWithQueries(t *testing.T) {
+	model, hypr := seededModel(t)
+	defer hypr.AssertExpectations(t)
+
+	snap, err := model.GetSnapshot(context.Background())
+	require.NoError(t, err)
+
+	workspaces, err := model.GetWorkspaces(context.Background())
+	require.NoError(t, err)
+	activeWs, err := model.GetActiveWorkspace(context.Background())
+	require.NoError(t, err)
+
+	assert.Len(t, snap.Monitors, 2)
+	assert.Equal(t, workspaces, snap.Workspaces)
+	assert.Equal(t, activeWs, snap.ActiveWorkspace)
+	assert.Equal(t, "0xa", snap.ActiveWindow.Address)
+	assert.Len(t, snap.ClientsInWorkspace(2), 1)
+}
+
+func TestModel_WaitSettledReturnsImmediatelyWithoutDispatch(t *testing.T) {
+	model := NewModel(new(mockHyprctl))
+
+	start := time.Now()
+	model.WaitSettled()
+
+	assert.Less(t, time.Since(start), modelSettleQuiet)
+}
+
+func TestModel_WaitSettledWaitsForQuietAfterDispatch(t *testing.T) {
+	model := NewModel(new(mockHyprctl))
+	model.MarkDispatched()
+
+	start := time.Now()
+	model.WaitSettled()
+
+	assert.GreaterOrEqual(t, time.Since(start), modelSettleQuiet)
+	assert.Less(t, time.Since(start), modelSettleMax+modelSettleQuiet)
+}
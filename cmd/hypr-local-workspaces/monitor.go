@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FindTargetMonitor resolves target relative to the monitor with ID currentID: "next" and "prev" cycle through
+// monitors in ID order, "left" and "right" pick the closest monitor on that side of the layout, "#N" is the Nth
+// monitor from the left and a plain number is a Hyprland monitor ID. Anything else is matched against connector
+// names and descriptions.
+func FindTargetMonitor(monitors []MonitorDTO, currentID int, target string) (MonitorDTO, error) {
+	sorted := append([]MonitorDTO{}, monitors...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	current := -1
+	for i, mon := range sorted {
+		if mon.ID == currentID {
+			current = i
+		}
+	}
+
+	if current == -1 {
+		return MonitorDTO{}, fmt.Errorf("current monitor (ID %d) not found", currentID)
+	}
+
+	switch target {
+	case "next":
+		return sorted[(current+1)%len(sorted)], nil
+	case "prev":
+		return sorted[(current+len(sorted)-1)%len(sorted)], nil
+	case "left", "right":
+		return closestMonitor(sorted, sorted[current], target)
+	}
+
+	for _, mon := range sorted {
+		if mon.Name == target || mon.Description == target {
+			return mon, nil
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(target, "#"); ok {
+		position, err := strconv.Atoi(rest)
+		if err != nil || position < 1 || position > len(sorted) {
+			return MonitorDTO{}, fmt.Errorf("no monitor at position %q, there are %d", rest, len(sorted))
+		}
+
+		// Left to right, then top to bottom
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if sorted[i].X != sorted[j].X {
+				return sorted[i].X < sorted[j].X
+			}
+
+			return sorted[i].Y < sorted[j].Y
+		})
+
+		return sorted[position-1], nil
+	}
+
+	if id, err := strconv.Atoi(target); err == nil {
+		for _, mon := range sorted {
+			if mon.ID == id {
+				return mon, nil
+			}
+		}
+
+		return MonitorDTO{}, fmt.Errorf("no monitor with ID %d", id)
+	}
+
+	return MonitorDTO{}, fmt.Errorf("no monitor named %q", target)
+}
+
+// closestMonitor returns the monitor nearest to from horizontally on the given side, preferring the one most in
+// line with it vertically.
+func closestMonitor(monitors []MonitorDTO, from MonitorDTO, side string) (MonitorDTO, error) {
+	found := false
+	var closest MonitorDTO
+	for _, mon := range monitors {
+		dx := mon.X - from.X
+		if side == "left" {
+			dx = -dx
+		}
+
+		if dx <= 0 {
+			continue
+		}
+
+		if !found || dx < distanceX(closest, from) || (dx == distanceX(closest, from) && distanceY(mon, from) < distanceY(closest, from)) {
+			closest = mon
+			found = true
+		}
+	}
+
+	if !found {
+		return MonitorDTO{}, fmt.Errorf("no monitor to the %s of %s", side, from.Name)
+	}
+
+	return closest, nil
+}
+
+func distanceX(a, b MonitorDTO) int {
+	return abs(a.X - b.X)
+}
+
+func distanceY(a, b MonitorDTO) int {
+	return abs(a.Y - b.Y)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	MonitorIdentityDescription = "description" // Make, model and serial, falling back to the connector name
+	MonitorIdentityName        = "name"        // Connector name, e.g. DP-2
+	MonitorIdentityID          = "id"          // Hyprland's monitor ID, which changes across hotplugs
+)
+
+func NewMonitorSlots(path, identity string) *MonitorSlots {
+	return &MonitorSlots{path: path, identity: identity}
+}
+
+// MonitorSlotsPath returns the file persisting which slot each monitor identity encodes in workspace names.
+func MonitorSlotsPath() (string, error) {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("locating state directory: %w", err)
+		}
+
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(stateDir, "hypr-local-workspaces", "monitor-slots.json"), nil
+}
+
+// ReadOnly returns slots that read from the same file but never write it. Monitors seen for the first time are
+// still assigned a slot, for as long as the returned value is used.
+func (s *MonitorSlots) ReadOnly() *MonitorSlots {
+	if s == nil {
+		return nil
+	}
+
+	return &MonitorSlots{path: s.path, identity: s.identity, readOnly: true}
+}
+
+// Identity returns the stable key mon is known by. Without slots, that is its connector name.
+func (s *MonitorSlots) Identity(mon MonitorDTO) string {
+	if s != nil && s.identity == MonitorIdentityDescription && mon.Description != "" {
+		return "description:" + mon.Description
+	}
+
+	return "name:" + mon.Name
+}
+
+// Identities maps the Hyprland IDs of monitors to the keys they are known by. Identical monitors report the same
+// description, so monitors sharing an identity are told apart by their connector name as well.
+func (s *MonitorSlots) Identities(monitors []MonitorDTO) map[int]string {
+	count := map[string]int{}
+	for _, mon := range monitors {
+		count[s.Identity(mon)]++
+	}
+
+	identities := map[int]string{}
+	for _, mon := range monitors {
+		identity := s.Identity(mon)
+		if count[identity] > 1 {
+			identity += "|name:" + mon.Name
+		}
+
+		identities[mon.ID] = identity
+	}
+
+	return identities
+}
+
+// Resolve maps the Hyprland IDs of monitors to the slots their workspace names encode. Identities seen for the
+// first time get their current Hyprland ID as slot when it is free, so names created before slots existed stay
+// valid, and the lowest free slot otherwise. New assignments are persisted before returning, when the state file
+// can be written.
+func (s *MonitorSlots) Resolve(monitors []MonitorDTO) (map[int]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.readOnly {
+		slots, err := s.load()
+		if err != nil {
+			return nil, err
+		}
+
+		resolved, _ := s.assign(monitors, slots)
+		return resolved, nil
+	}
+
+	var resolved map[int]int
+	err := updateState(s.path, "monitor slots", func(slots map[string]int) bool {
+		var changed bool
+		resolved, changed = s.assign(monitors, slots)
+		return changed
+	})
+
+	// An unwritable state directory only costs persistence: the new assignments still hold for this run, and a
+	// monitor keeps getting its Hyprland ID as slot whenever that is free
+	if resolved == nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+// assign resolves the slots of monitors from the persisted slots, adding the identities seen for the first time.
+// It reports whether slots changed.
+func (s *MonitorSlots) assign(monitors []MonitorDTO, slots map[string]int) (map[int]int, bool) {
+	taken := map[int]bool{}
+	for _, slot := range slots {
+		taken[slot] = true
+	}
+
+	// Lowest Hyprland ID first, so assignment doesn't depend on the order monitors were reported in
+	sorted := append([]MonitorDTO{}, monitors...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	identities := s.Identities(monitors)
+	used := map[int]bool{}
+
+	resolved := map[int]int{}
+	changed := false
+	for _, mon := range sorted {
+		identity := identities[mon.ID]
+
+		slot, ok := slots[identity]
+		if shared := s.Identity(mon); !ok && identity != shared {
+			// The first of identical monitors keeps the slot it had while it was the only one connected
+			slot, ok = slots[shared]
+			ok = ok && !used[slot]
+			if ok {
+				slots[identity] = slot
+				changed = true
+			}
+		}
+
+		if !ok {
+			slot = mon.ID
+			if slot < 0 || taken[slot] {
+				slot = nextFreeSlot(taken)
+			}
+
+			slots[identity] = slot
+			taken[slot] = true
+			changed = true
+		}
+
+		resolved[mon.ID] = slot
+		used[slot] = true
+	}
+
+	return resolved, changed
+}
+
+func nextFreeSlot(taken map[int]bool) int {
+	slot := 0
+	for taken[slot] {
+		slot++
+	}
+
+	return slot
+}
+
+func (s *MonitorSlots) load() (map[string]int, error) {
+	return loadState[int](s.path, "monitor slots")
+}
+
+// slotOf returns the slot monitorID encodes according to slots, defaulting to the ID itself.
+func slotOf(slots map[int]int, monitorID int) int {
+	if slot, ok := slots[monitorID]; ok {
+		return slot
+	}
+
+	return monitorID
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorSlots_KeepSlotAcrossHotplug(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+
+	slots, err := NewMonitorSlots(path, MonitorIdentityName).Resolve([]MonitorDTO{{ID: 0, Name: "eDP-1"}, {ID: 1, Name: "DP-2"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 0, 1: 1}, slots, "first sight keeps Hyprland's IDs")
+
+	// Undocked and docked again: DP-2 comes back with a new ID, and a fresh process reads the persisted slots
+	slots, err = NewMonitorSlots(path, MonitorIdentityName).Resolve([]MonitorDTO{{ID: 0, Name: "eDP-1"}, {ID: 3, Name: "DP-2"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 0, 3: 1}, slots)
+}
+
+func TestMonitorSlots_TakenIDGetsLowestFreeSlot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"name:eDP-1": 0, "name:DP-2": 2}`), 0o600))
+
+	slots, err := NewMonitorSlots(path, MonitorIdentityName).Resolve([]MonitorDTO{{ID: 2, Name: "HDMI-A-1"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{2: 1}, slots)
+}
+
+func TestMonitorSlots_ReadOnlyDoesNotPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+
+	slots, err := NewMonitorSlots(path, MonitorIdentityName).ReadOnly().Resolve([]MonitorDTO{{ID: 1, Name: "DP-2"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 1}, slots)
+	assert.NoFileExists(t, path)
+}
+
+func TestMonitorSlots_UnwritableStateStillResolves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+	// A directory in the way of the lock file makes every save fail
+	require.NoError(t, os.Mkdir(path+".lock", 0o700))
+
+	slots, err := NewMonitorSlots(path, MonitorIdentityName).Resolve([]MonitorDTO{{ID: 1, Name: "DP-2"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{1: 1}, slots)
+	assert.NoFileExists(t, path)
+}
+
+func TestMonitorSlots_IdenticalMonitorsGetDistinctSlots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+	const dell = "Dell Inc. DELL U2720Q"
+
+	// The first one was connected alone before
+	slots, err := NewMonitorSlots(path, MonitorIdentityDescription).Resolve([]MonitorDTO{{ID: 0, Name: "DP-1", Description: dell}})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 0}, slots)
+
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1", Description: dell}, {ID: 1, Name: "DP-2", Description: dell}}
+	slots, err = NewMonitorSlots(path, MonitorIdentityDescription).Resolve(monitors)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 0, 1: 1}, slots)
+
+	// Swapping their Hyprland IDs doesn't swap their slots
+	monitors = []MonitorDTO{{ID: 0, Name: "DP-2", Description: dell}, {ID: 1, Name: "DP-1", Description: dell}}
+	slots, err = NewMonitorSlots(path, MonitorIdentityDescription).Resolve(monitors)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 1, 1: 0}, slots)
+}
+
+func TestMonitorSlots_Identity(t *testing.T) {
+	byDescription := NewMonitorSlots("", MonitorIdentityDescription)
+	byName := NewMonitorSlots("", MonitorIdentityName)
+	mon := MonitorDTO{ID: 1, Name: "DP-2", Description: "Dell Inc. DELL U2720Q ABC123"}
+
+	assert.Equal(t, "description:Dell Inc. DELL U2720Q ABC123", byDescription.Identity(mon))
+	assert.Equal(t, "name:HEADLESS-1", byDescription.Identity(MonitorDTO{ID: 1, Name: "HEADLESS-1"}), "no description to go by")
+	assert.Equal(t, "name:DP-2", byName.Identity(mon))
+}
+
+func TestMonitorSlots_MalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := NewMonitorSlots(path, MonitorIdentityName).Resolve([]MonitorDTO{{ID: 0, Name: "DP-1"}})
+
+	assert.ErrorContains(t, err, "decoding monitor slots")
+}
+
+func TestInitWorkspaces_Simulated_EncodesPersistedSlots(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	sim.AddMonitor("HDMI-A-1")
+
+	// Hyprland handed out the IDs the other way around last time
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"name:DP-1": 1, "name:HDMI-A-1": 0}`), 0o600))
+	action := NewAction(sim, sim).WithMonitorSlots(NewMonitorSlots(path, MonitorIdentityName))
+
+	require.NoError(t, action.InitWorkspaces(context.Background()))
+	assert.Equal(t, []string{"1\u200c\u200b"}, sim.WorkspaceNames(0))
+	assert.Equal(t, []string{"1\u200b\u200b"}, sim.WorkspaceNames(1))
+
+	sim.AddClient(1)
+	require.NoError(t, action.GoToWorkspace(context.Background(), 1, true))
+	assert.Equal(t, "2\u200c\u200c", sim.ActiveWorkspaceName())
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTargetMonitor(t *testing.T) {
+	// HDMI-A-1 | DP-1 | DP-2, with eDP-1 below DP-1
+	monitors := []MonitorDTO{
+		{ID: 0, Name: "DP-1", X: 1920, Y: 0},
+		{ID: 2, Name: "DP-2", Description: "Dell Inc. U2720Q", X: 3840, Y: 0},
+		{ID: 1, Name: "HDMI-A-1", X: 0, Y: 0},
+		{ID: 3, Name: "eDP-1", X: 1920, Y: 1080},
+	}
+
+	for target, want := range map[string]string{
+		"next":             "HDMI-A-1",
+		"prev":             "eDP-1",
+		"left":             "HDMI-A-1",
+		"right":            "DP-2",
+		"DP-2":             "DP-2",
+		"Dell Inc. U2720Q": "DP-2",
+		"#1":               "HDMI-A-1",
+		"#2":               "DP-1",
+		"#3":               "eDP-1",
+		"#4":               "DP-2",
+		"2":                "DP-2",
+	} {
+		mon, err := FindTargetMonitor(monitors, 0, target)
+		require.NoError(t, err, target)
+		assert.Equal(t, want, mon.Name, target)
+	}
+
+	mon, err := FindTargetMonitor(monitors, 3, "next")
+	require.NoError(t, err)
+	assert.Equal(t, "DP-1", mon.Name, "next wraps around")
+
+	mon, err = FindTargetMonitor(monitors, 3, "right")
+	require.NoError(t, err)
+	assert.Equal(t, "DP-2", mon.Name)
+
+	_, err = FindTargetMonitor(monitors, 1, "left")
+	assert.Error(t, err)
+
+	_, err = FindTargetMonitor(monitors, 0, "DP-9")
+	assert.Error(t, err)
+
+	_, err = FindTargetMonitor(monitors, 7, "next")
+	assert.Error(t, err)
+
+	for _, target := range []string{"#0", "#5", "#x", "7"} {
+		_, err = FindTargetMonitor(monitors, 0, target)
+		assert.Error(t, err, target)
+	}
+}
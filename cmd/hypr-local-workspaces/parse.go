@@ -9,43 +9,194 @@ import (
 	"strings"
 )
 
-func parseGotoArgs(args []string) (int, []string, error) {
+func parseGotoArgs(args []string) (string, int, bool, []string, error) {
+	fs := flag.NewFlagSet("goto", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	backAndForth := fs.Bool("back-and-forth", false, "Go back when the target is already active")
+
+	if err := fs.Parse(args); err != nil {
+		return "", 0, false, nil, err
+	}
+
+	pos := fs.Args()
+	if len(pos) < 1 {
+		return "", 0, false, nil, errors.New("usage: hypr-local-workspaces goto [--back-and-forth] <[monitor:]1..N> [global flags]")
+	}
+
+	monitor, v, err := parseWorkspaceAddress(pos[0])
+	if err != nil || v < 1 {
+		return "", 0, false, nil, errors.New("goto index must be a positive integer, optionally prefixed with a monitor and ':'")
+	}
+
+	return monitor, v, *backAndForth, pos[1:], nil
+}
+
+func parseMoveArgs(args []string) (string, int, bool, []string, error) {
+	fs := flag.NewFlagSet("move", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	all := fs.Bool("all", false, "Apply to all")
+
+	if err := fs.Parse(args); err != nil {
+		return "", 0, false, nil, err
+	}
+
+	pos := fs.Args()
+	if len(pos) < 1 {
+		return "", 0, false, nil, errors.New("usage: hypr-local-workspaces move <[monitor:]1..N> [--all] [global flags]")
+	}
+
+	monitor, v, err := parseWorkspaceAddress(pos[0])
+	if err != nil {
+		return "", 0, false, nil, errors.New("move expects an integer, optionally prefixed with a monitor and ':'")
+	}
+
+	if v < 1 {
+		return "", 0, false, nil, errors.New("move index must be a positive integer")
+	}
+
+	return monitor, v, *all, pos[1:], nil
+}
+
+// parseWorkspaceAddress splits an address like "DP-2:3" into the monitor, as FindTargetMonitor resolves it, and
+// the position. A plain position leaves the monitor empty, meaning the focused one.
+func parseWorkspaceAddress(address string) (string, int, error) {
+	monitor := ""
+	if i := strings.LastIndex(address, ":"); i != -1 {
+		monitor, address = parseMonitorTarget(address[:i]), address[i+1:]
+		if monitor == "" {
+			return "", 0, errors.New("empty monitor in workspace address")
+		}
+	}
+
+	v, err := strconv.Atoi(address)
+	return monitor, v, err
+}
+
+func parseSwapArgs(args []string) (int, []string, error) {
 	if len(args) < 1 {
-		return 0, nil, errors.New("usage: hypr-local-workspaces goto <1..N> [global flags]")
+		return 0, nil, errors.New("usage: hypr-local-workspaces swap <1..N> [global flags]")
 	}
 
 	v, err := strconv.Atoi(args[0])
-	if err != nil || v < 1 || v > 9 {
-		return 0, nil, errors.New("goto index must be a digit 1..N")
+	if err != nil || v < 1 {
+		return 0, nil, errors.New("swap index must be a positive integer")
 	}
 
 	return v, args[1:], nil
 }
 
-func parseMoveArgs(args []string) (int, bool, []string, error) {
-	fs := flag.NewFlagSet("move", flag.ContinueOnError)
+// parseMoveWorkspaceArgs returns either the target position or a direction, "left" or "right".
+func parseMoveWorkspaceArgs(args []string) (int, string, []string, error) {
+	if len(args) < 1 {
+		return 0, "", nil, errors.New("usage: hypr-local-workspaces move-workspace <1..N|left|right> [global flags]")
+	}
+
+	val := strings.ToLower(args[0])
+	if val == "left" || val == "right" {
+		return 0, val, args[1:], nil
+	}
+
+	v, err := strconv.Atoi(val)
+	if err != nil || v < 1 {
+		return 0, "", nil, errors.New("move-workspace expects a positive integer, 'left' or 'right'")
+	}
+
+	return v, "", args[1:], nil
+}
+
+// parseMoveToMonitorArgs returns the target monitor, the 1-based --index (0 when not given) and --all.
+func parseMoveToMonitorArgs(args []string) (string, int, bool, []string, error) {
+	fs := flag.NewFlagSet("move-to-monitor", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	all := fs.Bool("all", false, "Apply to all")
+	index := fs.Int("index", 0, "Local workspace on the target monitor, instead of the one it shows")
 
 	if err := fs.Parse(args); err != nil {
-		return 0, false, nil, err
+		return "", 0, false, nil, err
 	}
 
 	pos := fs.Args()
 	if len(pos) < 1 {
-		return 0, false, nil, errors.New("usage: hypr-local-workspaces move <1..N> [--all] [global flags]")
+		return "", 0, false, nil, errors.New("usage: hypr-local-workspaces move-to-monitor [--all] [--index N] <next|prev|left|right|name> [global flags]")
 	}
 
-	v, err := strconv.Atoi(pos[0])
-	if err != nil {
-		return 0, false, nil, errors.New("move expects an integer digit")
+	// 0 is what --index defaults to, so check whether it was given rather than its value
+	if isFlagSet(fs, "index") && *index < 1 {
+		return "", 0, false, nil, errors.New("move-to-monitor --index must be a positive integer")
+	}
+
+	return parseMonitorTarget(pos[0]), *index, *all, pos[1:], nil
+}
+
+// parseMoveWorkspaceToMonitorArgs returns the target monitor and the 1-based --index (0 when not given).
+func parseMoveWorkspaceToMonitorArgs(args []string) (string, int, []string, error) {
+	fs := flag.NewFlagSet("move-workspace-to-monitor", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	index := fs.Int("index", 0, "Position on the target monitor, instead of after its last workspace")
+
+	if err := fs.Parse(args); err != nil {
+		return "", 0, nil, err
+	}
+
+	pos := fs.Args()
+	if len(pos) < 1 {
+		return "", 0, nil, errors.New("usage: hypr-local-workspaces move-workspace-to-monitor [--index N] <next|prev|left|right|name> [global flags]")
+	}
+
+	if isFlagSet(fs, "index") && *index < 1 {
+		return "", 0, nil, errors.New("move-workspace-to-monitor --index must be a positive integer")
+	}
+
+	return parseMonitorTarget(pos[0]), *index, pos[1:], nil
+}
+
+// isFlagSet reports whether the flag called name was given on the command line parsed by fs.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// parseMonitorTarget lowercases the relative monitor targets, leaving monitor names as given.
+func parseMonitorTarget(target string) string {
+	if lower := strings.ToLower(target); lower == "next" || lower == "prev" || lower == "left" || lower == "right" {
+		return lower
+	}
+
+	return target
+}
+
+// parseDecodeArgs splits the names to decode from the global flags after them. Local names start with a digit,
+// so the first argument starting with "-" ends the names.
+func parseDecodeArgs(args []string) ([]string, []string, error) {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return args[:i], args[i:], nil
+		}
 	}
 
-	if v < 1 || v > 9 {
-		return 0, false, nil, errors.New("move index must be a digit 1..N")
+	return args, nil, nil
+}
+
+func parseInitArgs(args []string) (bool, []string, error) {
+	if len(args) > 0 && args[0] == "--adopt" {
+		return true, args[1:], nil
 	}
 
-	return v, *all, pos[1:], nil
+	return false, args, nil
+}
+
+func parseVerifyArgs(args []string) (bool, []string, error) {
+	if len(args) > 0 && args[0] == "--repair" {
+		return true, args[1:], nil
+	}
+
+	return false, args, nil
 }
 
 func parseCycleArgs(args []string) (string, []string, error) {
@@ -61,18 +212,210 @@ func parseCycleArgs(args []string) (string, []string, error) {
 	return val, args[1:], nil
 }
 
+// parseActionCommand parses the arguments of the subcommands that act on workspaces, such as goto and move, including trailing global flags.
+func parseActionCommand(subcmd string, subArgs []string) (ActionCommand, error) {
+	cmd := ActionCommand{Name: subcmd}
+
+	var trailing []string
+	var err error
+	switch subcmd {
+	case "goto":
+		var targetWorkspace int
+		cmd.Monitor, targetWorkspace, cmd.BackAndForth, trailing, err = parseGotoArgs(subArgs)
+		cmd.Index = targetWorkspace - 1
+
+	case "back":
+		trailing = subArgs
+
+	case "move":
+		var targetWorkspace int
+		cmd.Monitor, targetWorkspace, cmd.All, trailing, err = parseMoveArgs(subArgs)
+		cmd.Index = targetWorkspace - 1
+
+	case "cycle":
+		cmd.Direction, trailing, err = parseCycleArgs(subArgs)
+
+	case "swap":
+		var targetWorkspace int
+		targetWorkspace, trailing, err = parseSwapArgs(subArgs)
+		cmd.Index = targetWorkspace - 1
+
+	case "move-workspace":
+		var targetWorkspace int
+		targetWorkspace, cmd.Direction, trailing, err = parseMoveWorkspaceArgs(subArgs)
+		cmd.Index = targetWorkspace - 1
+
+	case "move-to-monitor":
+		var targetWorkspace int
+		cmd.Monitor, targetWorkspace, cmd.All, trailing, err = parseMoveToMonitorArgs(subArgs)
+		cmd.Index = targetWorkspace - 1
+
+	case "move-workspace-to-monitor":
+		var targetWorkspace int
+		cmd.Monitor, targetWorkspace, trailing, err = parseMoveWorkspaceToMonitorArgs(subArgs)
+		cmd.Index = targetWorkspace - 1
+
+	default:
+		return cmd, fmt.Errorf("unknown subcommand: %q", subcmd)
+	}
+
+	if err != nil {
+		return cmd, err
+	}
+
+	cmd.Globals, err = parseTrailingGlobalFlags(trailing)
+	return cmd, err
+}
+
+func parseMigrateArgs(args []string) (Codec, Codec, []string, error) {
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[1], "-") {
+		return nil, nil, nil, errors.New("usage: hypr-local-workspaces migrate <from> <to> [global flags]")
+	}
+
+	from, err := CodecByName(args[0])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	to, err := CodecByName(args[1])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if from == to {
+		return nil, nil, nil, errors.New("migrate needs two different codecs")
+	}
+
+	return from, to, args[2:], nil
+}
+
+func parseDaemonArgs(args []string) (string, []string, error) {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		return "run", args, nil
+	}
+
+	val := strings.ToLower(args[0])
+	if val != "run" && val != "status" && val != "stop" {
+		return "", nil, errors.New("daemon command must be 'run', 'status' or 'stop'")
+	}
+
+	return val, args[1:], nil
+}
+
+// defaultGlobalFlags returns the global flags of an invocation that passes none.
+func defaultGlobalFlags() GlobalFlags {
+	return GlobalFlags{
+		Compact:         true,
+		IPC:             IPCAuto,
+		UseDaemon:       true,
+		Timeout:         HyprctlTimeout,
+		Retries:         DefaultRetries,
+		RetryDelay:      DefaultRetryDelay,
+		Wait:            DefaultReadyWait,
+		MonitorIdentity: MonitorIdentityDescription,
+	}
+}
+
 func parseTrailingGlobalFlags(args []string) (GlobalFlags, error) {
 	fs := flag.NewFlagSet("global", flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	noCompact := fs.Bool("no-compact", false, "Disable compact mode")
+	ipc := fs.String("ipc", IPCAuto, "IPC mode: auto, socket or hyprctl")
+	noDaemon := fs.Bool("no-daemon", false, "Never forward to a running daemon")
+	timeout := fs.Duration("timeout", HyprctlTimeout, "Timeout for each request to Hyprland")
+	deadline := fs.Duration("deadline", 0, "Deadline for the whole command, 0 for none")
+	retries := fs.Int("retries", DefaultRetries, "Retries for requests that failed transiently")
+	retryDelay := fs.Duration("retry-delay", DefaultRetryDelay, "First retry delay, doubled on every retry")
+	wait := fs.Duration("wait", DefaultReadyWait, "How long init and the daemon wait for Hyprland, 0 to not wait")
+	record := fs.String("record", "", "Write every request to Hyprland to this trace file")
+	dryRun := fs.Bool("dry-run", false, "Print the planned dispatches instead of running them")
+	asJSON := fs.Bool("json", false, "Print output as JSON")
+	monitorIdentity := fs.String("monitor-identity", MonitorIdentityDescription, "What identifies a monitor: description, name or id")
+	maxWorkspaces := fs.Int("max-workspaces", 0, "Local workspaces a monitor may grow to, 0 for unlimited")
+	codecName := fs.String("codec", "", "Encoding of new names: "+codecNames())
+	labels := NewLabels()
+	fs.Var(labels, "label", "Label shown in a workspace's name, as N=label or MONITOR:N=label (repeatable)")
 
+	defaults := defaultGlobalFlags()
 	if err := fs.Parse(args); err != nil {
-		return GlobalFlags{Compact: true}, err
+		return defaults, err
 	}
 
 	if len(fs.Args()) > 0 {
-		return GlobalFlags{Compact: true}, fmt.Errorf("unexpected arguments: %v", fs.Args())
+		return defaults, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	switch *ipc {
+	case IPCAuto, IPCSocket, IPCHyprctl:
+	default:
+		return defaults, fmt.Errorf("--ipc must be one of %s, %s or %s", IPCAuto, IPCSocket, IPCHyprctl)
+	}
+
+	if *timeout <= 0 {
+		return defaults, errors.New("--timeout must be positive")
+	}
+
+	if *deadline < 0 {
+		return defaults, errors.New("--deadline must not be negative")
+	}
+
+	if *retries < 0 {
+		return defaults, errors.New("--retries must not be negative")
+	}
+
+	if *retryDelay < 0 {
+		return defaults, errors.New("--retry-delay must not be negative")
+	}
+
+	if *wait < 0 {
+		return defaults, errors.New("--wait must not be negative")
+	}
+
+	switch *monitorIdentity {
+	case MonitorIdentityDescription, MonitorIdentityName, MonitorIdentityID:
+	default:
+		return defaults, fmt.Errorf("--monitor-identity must be one of %s, %s or %s", MonitorIdentityDescription, MonitorIdentityName, MonitorIdentityID)
+	}
+
+	if *maxWorkspaces < 0 {
+		return defaults, errors.New("--max-workspaces must not be negative")
+	}
+
+	// nil tells a daemon that the command leaves labels and codec to it
+	if labels.Empty() {
+		labels = nil
+	}
+
+	var codec Codec
+	if *codecName != "" {
+		var err error
+		if codec, err = CodecByName(*codecName); err != nil {
+			return defaults, fmt.Errorf("--codec: %w", err)
+		}
+	}
+
+	// The daemon runs with the clients and monitor slots it was started with
+	directOnly := false
+	for _, name := range []string{"ipc", "timeout", "retries", "retry-delay", "monitor-identity"} {
+		directOnly = directOnly || isFlagSet(fs, name)
 	}
 
-	return GlobalFlags{Compact: !*noCompact}, nil
+	return GlobalFlags{
+		Compact:         !*noCompact,
+		IPC:             *ipc,
+		UseDaemon:       !*noDaemon,
+		Timeout:         *timeout,
+		Deadline:        *deadline,
+		Retries:         *retries,
+		RetryDelay:      *retryDelay,
+		Wait:            *wait,
+		Record:          *record,
+		DryRun:          *dryRun,
+		JSON:            *asJSON,
+		MaxWorkspaces:   *maxWorkspaces,
+		MonitorIdentity: *monitorIdentity,
+		Labels:          labels,
+		Codec:           codec,
+		DirectOnly:      directOnly,
+	}, nil
 }
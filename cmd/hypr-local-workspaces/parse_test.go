@@ -3,12 +3,13 @@ package main
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestParseGotoArgs_Success_WithTrailing(t *testing.T) {
-	v, trailing, err := parseGotoArgs([]string{"3", "--no-compact"})
+	_, v, _, trailing, err := parseGotoArgs([]string{"3", "--no-compact"})
 	assert.NoError(t, err)
 	assert.Equal(t, 3, v)
 	assert.Equal(t, []string{"--no-compact"}, trailing)
@@ -16,32 +17,42 @@ func TestParseGotoArgs_Success_WithTrailing(t *testing.T) {
 
 func TestParseGotoArgs_Errors(t *testing.T) {
 	// Missing positional
-	_, _, err := parseGotoArgs([]string{})
+	_, _, _, _, err := parseGotoArgs([]string{})
 	assert.Error(t, err)
 
 	// Non-integer
-	_, _, err = parseGotoArgs([]string{"x"})
+	_, _, _, _, err = parseGotoArgs([]string{"x"})
 	assert.Error(t, err)
 
 	// Out of range low
-	_, _, err = parseGotoArgs([]string{"0"})
+	_, _, _, _, err = parseGotoArgs([]string{"0"})
 	assert.Error(t, err)
 
-	// Out of range high
-	_, _, err = parseGotoArgs([]string{"10"})
+	// Negative
+	_, _, _, _, err = parseGotoArgs([]string{"-3"})
 	assert.Error(t, err)
 }
 
+func TestParseGotoArgs_MultiDigit(t *testing.T) {
+	_, v, _, _, err := parseGotoArgs([]string{"12"})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, v)
+
+	_, v, _, _, err = parseMoveArgs([]string{"15"})
+	assert.NoError(t, err)
+	assert.Equal(t, 15, v)
+}
+
 func TestParseMoveArgs_Success(t *testing.T) {
 	// With flag and trailing
-	v, all, trailing, err := parseMoveArgs([]string{"--all", "2", "--no-compact"})
+	_, v, all, trailing, err := parseMoveArgs([]string{"--all", "2", "--no-compact"})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, v)
 	assert.True(t, all)
 	assert.Equal(t, []string{"--no-compact"}, trailing)
 
 	// Minimal
-	v, all, trailing, err = parseMoveArgs([]string{"2"})
+	_, v, all, trailing, err = parseMoveArgs([]string{"2"})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, v)
 	assert.False(t, all)
@@ -50,22 +61,54 @@ func TestParseMoveArgs_Success(t *testing.T) {
 
 func TestParseMoveArgs_Errors(t *testing.T) {
 	// No positional
-	_, _, _, err := parseMoveArgs([]string{"--all"})
+	_, _, _, _, err := parseMoveArgs([]string{"--all"})
 	assert.Error(t, err)
 
 	// Non-integer positional
-	_, _, _, err = parseMoveArgs([]string{"x"})
+	_, _, _, _, err = parseMoveArgs([]string{"x"})
 	assert.Error(t, err)
 
 	// Out of range
-	_, _, _, err = parseMoveArgs([]string{"0"})
+	_, _, _, _, err = parseMoveArgs([]string{"0"})
 	assert.Error(t, err)
 
 	// Unknown flag causes parse error
-	_, _, _, err = parseMoveArgs([]string{"--wat"})
+	_, _, _, _, err = parseMoveArgs([]string{"--wat"})
 	assert.Error(t, err)
 }
 
+func TestParseWorkspaceAddresses(t *testing.T) {
+	monitor, v, _, trailing, err := parseGotoArgs([]string{"DP-2:3", "--no-compact"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DP-2", monitor)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, []string{"--no-compact"}, trailing)
+
+	monitor, v, all, _, err := parseMoveArgs([]string{"--all", "1:4"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", monitor)
+	assert.Equal(t, 4, v)
+	assert.True(t, all)
+
+	monitor, v, _, _, err = parseGotoArgs([]string{"#2:1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "#2", monitor)
+	assert.Equal(t, 1, v)
+
+	// Directions are case-insensitive, like for move-to-monitor
+	monitor, _, _, _, err = parseGotoArgs([]string{"Next:2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "next", monitor)
+
+	for _, address := range []string{":3", "DP-2:", "DP-2:x", "DP-2:0"} {
+		_, _, _, _, err = parseGotoArgs([]string{address})
+		assert.Error(t, err, address)
+
+		_, _, _, _, err = parseMoveArgs([]string{address})
+		assert.Error(t, err, address)
+	}
+}
+
 func TestParseCycleArgs_Success_WithTrailing(t *testing.T) {
 	dir, trailing, err := parseCycleArgs([]string{"next", "--no-compact"})
 	assert.NoError(t, err)
@@ -107,10 +150,301 @@ func TestParseTrailingGlobalFlags(t *testing.T) {
 }
 
 func TestParseMoveArgs_FlagAfterPos_TreatedAsTrailing(t *testing.T) {
-	v, all, trailing, err := parseMoveArgs([]string{"2", "--all", "--no-compact"})
+	_, v, all, trailing, err := parseMoveArgs([]string{"2", "--all", "--no-compact"})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, v)
 	// '--all' after positional should not be treated as subcommand flag
 	assert.False(t, all)
 	assert.Equal(t, []string{"--all", "--no-compact"}, trailing)
 }
+
+func TestParseTrailingGlobalFlags_IPC(t *testing.T) {
+	g, err := parseTrailingGlobalFlags([]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, IPCAuto, g.IPC)
+
+	g, err = parseTrailingGlobalFlags([]string{"--ipc", "socket"})
+	assert.NoError(t, err)
+	assert.Equal(t, IPCSocket, g.IPC)
+
+	_, err = parseTrailingGlobalFlags([]string{"--ipc", "smoke-signals"})
+	assert.Error(t, err)
+}
+
+func TestParseTrailingGlobalFlags_TimeoutAndDeadline(t *testing.T) {
+	g, err := parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, HyprctlTimeout, g.Timeout)
+	assert.Zero(t, g.Deadline)
+
+	g, err = parseTrailingGlobalFlags([]string{"--timeout", "500ms", "--deadline=3s"})
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, g.Timeout)
+	assert.Equal(t, 3*time.Second, g.Deadline)
+
+	_, err = parseTrailingGlobalFlags([]string{"--timeout", "0"})
+	assert.Error(t, err)
+
+	_, err = parseTrailingGlobalFlags([]string{"--deadline", "-1s"})
+	assert.Error(t, err)
+
+	_, err = parseTrailingGlobalFlags([]string{"--timeout", "soon"})
+	assert.Error(t, err)
+}
+
+func TestParseTrailingGlobalFlags_RetryAndWait(t *testing.T) {
+	g, err := parseTrailingGlobalFlags([]string{"--retries", "5", "--retry-delay", "10ms", "--wait", "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, g.Retries)
+	assert.Equal(t, 10*time.Millisecond, g.RetryDelay)
+	assert.Zero(t, g.Wait)
+
+	_, err = parseTrailingGlobalFlags([]string{"--retries", "-1"})
+	assert.Error(t, err)
+
+	_, err = parseTrailingGlobalFlags([]string{"--wait", "-1s"})
+	assert.Error(t, err)
+}
+
+func TestParseTrailingGlobalFlags_Record(t *testing.T) {
+	g, err := parseTrailingGlobalFlags([]string{"--record", "/tmp/trace.jsonl"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/trace.jsonl", g.Record)
+
+	g, err = parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, g.Record)
+}
+
+func TestParseTrailingGlobalFlags_DirectOnly(t *testing.T) {
+	for _, args := range [][]string{{"--ipc", "hyprctl"}, {"--timeout", "1s"}, {"--retries", "0"}, {"--retry-delay", "1s"}, {"--monitor-identity", "description"}} {
+		g, err := parseTrailingGlobalFlags(args)
+		assert.NoError(t, err)
+		assert.True(t, g.DirectOnly, "%v", args)
+	}
+
+	// The daemon applies these itself
+	g, err := parseTrailingGlobalFlags([]string{"--max-workspaces", "5", "--label", "1=web", "--codec", "tags", "--deadline", "1s", "--no-compact"})
+	assert.NoError(t, err)
+	assert.False(t, g.DirectOnly)
+}
+
+func TestParseTrailingGlobalFlags_DryRun(t *testing.T) {
+	g, err := parseTrailingGlobalFlags([]string{"--dry-run", "--json"})
+	assert.NoError(t, err)
+	assert.True(t, g.DryRun)
+	assert.True(t, g.JSON)
+
+	g, err = parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.False(t, g.DryRun)
+	assert.False(t, g.JSON)
+}
+
+func TestParseTrailingGlobalFlags_MaxWorkspaces(t *testing.T) {
+	g, err := parseTrailingGlobalFlags([]string{"--max-workspaces", "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, 12, g.MaxWorkspaces)
+
+	g, err = parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.Zero(t, g.MaxWorkspaces)
+
+	_, err = parseTrailingGlobalFlags([]string{"--max-workspaces", "-1"})
+	assert.Error(t, err)
+}
+
+func TestParseTrailingGlobalFlags_MonitorIdentity(t *testing.T) {
+	g, err := parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, MonitorIdentityDescription, g.MonitorIdentity)
+
+	g, err = parseTrailingGlobalFlags([]string{"--monitor-identity", "name"})
+	assert.NoError(t, err)
+	assert.Equal(t, MonitorIdentityName, g.MonitorIdentity)
+
+	_, err = parseTrailingGlobalFlags([]string{"--monitor-identity", "serial"})
+	assert.Error(t, err)
+}
+
+func TestParseTrailingGlobalFlags_Labels(t *testing.T) {
+	g, err := parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, g.Labels)
+
+	g, err = parseTrailingGlobalFlags([]string{"--label", "1=web", "--label", "DP-2:2=chat"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{0: "web", 1: "chat"}, g.Labels.For(MonitorDTO{Name: "DP-2"}))
+
+	_, err = parseTrailingGlobalFlags([]string{"--label", "web"})
+	assert.Error(t, err)
+}
+
+func TestParseTrailingGlobalFlags_Codec(t *testing.T) {
+	g, err := parseTrailingGlobalFlags(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, g.Codec)
+
+	g, err = parseTrailingGlobalFlags([]string{"--codec", "tags"})
+	assert.NoError(t, err)
+	assert.Equal(t, TagCodec, g.Codec)
+
+	_, err = parseTrailingGlobalFlags([]string{"--codec", "emoji"})
+	assert.Error(t, err)
+}
+
+func TestParseMigrateArgs(t *testing.T) {
+	from, to, trailing, err := parseMigrateArgs([]string{"zero-width", "tags", "--dry-run"})
+	assert.NoError(t, err)
+	assert.Equal(t, ZeroWidthCodec, from)
+	assert.Equal(t, TagCodec, to)
+	assert.Equal(t, []string{"--dry-run"}, trailing)
+
+	for _, args := range [][]string{nil, {"tags"}, {"tags", "--dry-run"}, {"tags", "tags"}, {"tags", "emoji"}} {
+		_, _, _, err := parseMigrateArgs(args)
+		assert.Error(t, err, "%v", args)
+	}
+}
+
+func TestParseDaemonArgs(t *testing.T) {
+	cmd, trailing, err := parseDaemonArgs([]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, "run", cmd)
+	assert.Empty(t, trailing)
+
+	cmd, trailing, err = parseDaemonArgs([]string{"--ipc", "socket"})
+	assert.NoError(t, err)
+	assert.Equal(t, "run", cmd)
+	assert.Equal(t, []string{"--ipc", "socket"}, trailing)
+
+	cmd, _, err = parseDaemonArgs([]string{"STOP"})
+	assert.NoError(t, err)
+	assert.Equal(t, "stop", cmd)
+
+	_, _, err = parseDaemonArgs([]string{"restart"})
+	assert.Error(t, err)
+}
+
+func TestParseActionCommand(t *testing.T) {
+	cmd, err := parseActionCommand("goto", []string{"3", "--no-compact"})
+	assert.NoError(t, err)
+	globals := defaultGlobalFlags()
+	globals.Compact = false
+	assert.Equal(t, ActionCommand{Name: "goto", Index: 2, Globals: globals}, cmd)
+
+	cmd, err = parseActionCommand("move", []string{"--all", "1", "--no-daemon"})
+	assert.NoError(t, err)
+	globals = defaultGlobalFlags()
+	globals.UseDaemon = false
+	assert.Equal(t, ActionCommand{Name: "move", Index: 0, All: true, Globals: globals}, cmd)
+
+	cmd, err = parseActionCommand("cycle", []string{"prev"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prev", cmd.Direction)
+
+	cmd, err = parseActionCommand("swap", []string{"12"})
+	assert.NoError(t, err)
+	assert.Equal(t, ActionCommand{Name: "swap", Index: 11, Globals: defaultGlobalFlags()}, cmd)
+
+	for _, args := range [][]string{nil, {"0"}, {"x"}} {
+		_, err = parseActionCommand("swap", args)
+		assert.Error(t, err, "%v", args)
+	}
+
+	cmd, err = parseActionCommand("move-workspace", []string{"2"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cmd.Index)
+	assert.Empty(t, cmd.Direction)
+
+	cmd, err = parseActionCommand("move-workspace", []string{"Left", "--no-compact"})
+	assert.NoError(t, err)
+	assert.Equal(t, "left", cmd.Direction)
+	assert.False(t, cmd.Globals.Compact)
+
+	for _, args := range [][]string{nil, {"0"}, {"up"}} {
+		_, err = parseActionCommand("move-workspace", args)
+		assert.Error(t, err, "%v", args)
+	}
+
+	cmd, err = parseActionCommand("move-to-monitor", []string{"--all", "--index", "3", "DP-2", "--no-compact"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DP-2", cmd.Monitor)
+	assert.Equal(t, 2, cmd.Index)
+	assert.True(t, cmd.All)
+	assert.False(t, cmd.Globals.Compact)
+
+	cmd, err = parseActionCommand("move-to-monitor", []string{"Next"})
+	assert.NoError(t, err)
+	assert.Equal(t, "next", cmd.Monitor)
+	assert.Equal(t, -1, cmd.Index, "the workspace the monitor shows")
+	assert.False(t, cmd.All)
+
+	for _, args := range [][]string{nil, {"--index", "-1", "next"}, {"--index", "0", "next"}, {"--all"}} {
+		_, err = parseActionCommand("move-to-monitor", args)
+		assert.Error(t, err, "%v", args)
+	}
+
+	cmd, err = parseActionCommand("move-workspace-to-monitor", []string{"--index", "1", "LEFT"})
+	assert.NoError(t, err)
+	assert.Equal(t, ActionCommand{Name: "move-workspace-to-monitor", Monitor: "left", Index: 0, Globals: defaultGlobalFlags()}, cmd)
+
+	for _, args := range [][]string{nil, {"--all", "next"}, {"--index", "0", "next"}, {"--index", "-2", "next"}} {
+		_, err = parseActionCommand("move-workspace-to-monitor", args)
+		assert.Error(t, err, "%v", args)
+	}
+
+	cmd, err = parseActionCommand("goto", []string{"--back-and-forth", "DP-2:3"})
+	assert.NoError(t, err)
+	assert.Equal(t, ActionCommand{Name: "goto", Index: 2, Monitor: "DP-2", BackAndForth: true, Globals: defaultGlobalFlags()}, cmd)
+
+	cmd, err = parseActionCommand("back", []string{"--no-compact"})
+	assert.NoError(t, err)
+	globals = defaultGlobalFlags()
+	globals.Compact = false
+	assert.Equal(t, ActionCommand{Name: "back", Globals: globals}, cmd)
+
+	_, err = parseActionCommand("back", []string{"2"})
+	assert.Error(t, err)
+
+	_, err = parseActionCommand("goto", []string{"3", "--wat"})
+	assert.Error(t, err)
+
+	_, err = parseActionCommand("teleport", nil)
+	assert.Error(t, err)
+}
+
+func TestParseVerifyArgs(t *testing.T) {
+	repair, trailing, err := parseVerifyArgs([]string{"--repair", "--dry-run"})
+	assert.NoError(t, err)
+	assert.True(t, repair)
+	assert.Equal(t, []string{"--dry-run"}, trailing)
+
+	repair, trailing, err = parseVerifyArgs([]string{"--json"})
+	assert.NoError(t, err)
+	assert.False(t, repair)
+	assert.Equal(t, []string{"--json"}, trailing)
+}
+
+func TestParseInitArgs(t *testing.T) {
+	adopt, trailing, err := parseInitArgs([]string{"--adopt", "--dry-run"})
+	assert.NoError(t, err)
+	assert.True(t, adopt)
+	assert.Equal(t, []string{"--dry-run"}, trailing)
+
+	adopt, trailing, err = parseInitArgs([]string{"--wait", "5s"})
+	assert.NoError(t, err)
+	assert.False(t, adopt)
+	assert.Equal(t, []string{"--wait", "5s"}, trailing)
+}
+
+func TestParseDecodeArgs(t *testing.T) {
+	names, trailing, err := parseDecodeArgs([]string{"1\u200b\u200b", "2\u200b\u200c", "--json"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1\u200b\u200b", "2\u200b\u200c"}, names)
+	assert.Equal(t, []string{"--json"}, trailing)
+
+	names, trailing, err = parseDecodeArgs(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+	assert.Empty(t, trailing)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// AcquirePidFile writes the current PID to path, refusing if another live process already holds it.
+// Stale files left behind by a crashed process are replaced. The returned function removes the file.
+func AcquirePidFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+			closeErr := f.Close()
+			if err == nil {
+				err = closeErr
+			}
+
+			if err != nil {
+				_ = os.Remove(path)
+				return nil, err
+			}
+
+			return func() { _ = os.Remove(path) }, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		pid, readErr := ReadPidFile(path)
+		if readErr == nil && ProcessAlive(pid) {
+			return nil, fmt.Errorf("daemon already running (pid %d)", pid)
+		}
+
+		// Stale or unreadable, take it over
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("could not acquire pid file %s", path)
+}
+
+// ReadPidFile returns the PID stored in path.
+func ReadPidFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid pid file %s", path)
+	}
+
+	return pid, nil
+}
+
+// ProcessAlive reports whether a process with the given PID exists.
+func ProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
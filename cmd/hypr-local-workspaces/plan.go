@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func NewPlanDispatcher() *planDispatcher {
+	return &planDispatcher{}
+}
+
+// Plan returns every command dispatched so far, in order.
+func (p *planDispatcher) Plan() []DispatchCmd {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]DispatchCmd{}, p.cmds...)
+}
+
+func (p *planDispatcher) add(cmds ...DispatchCmd) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cmds = append(p.cmds, cmds...)
+	return nil
+}
+
+func (p *planDispatcher) GoToWorkspace(ctx context.Context, wsName string) error {
+	return p.add(GoToWorkspaceCmd(wsName))
+}
+
+func (p *planDispatcher) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	return p.add(RenameWorkspaceCmd(id, wsNewName))
+}
+
+func (p *planDispatcher) FocusMonitor(ctx context.Context, monitorId int) error {
+	return p.add(FocusMonitorCmd(monitorId))
+}
+
+func (p *planDispatcher) MoveToWorkspace(ctx context.Context, wsName string) error {
+	return p.add(MoveToWorkspaceCmd(wsName))
+}
+
+func (p *planDispatcher) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	return p.add(MoveAddrToWorkspaceCmd(wsName, windowAddr))
+}
+
+func (p *planDispatcher) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	return p.add(cmds...)
+}
+
+// RunPlanned runs an action against live state with a dispatcher that only records, and returns the plan.
+func RunPlanned(action *Action, run func(action *Action) error) ([]DispatchCmd, error) {
+	plan := NewPlanDispatcher()
+
+	planned := *action
+	planned.dispatcher = plan
+	planned.slots = action.slots.ReadOnly()
+	planned.history = action.history.ReadOnly()
+	if err := run(&planned); err != nil {
+		return nil, err
+	}
+
+	return plan.Plan(), nil
+}
+
+// PrintPlan writes planned dispatches one per line, or as a JSON array. Zero-width characters in names are
+// escaped, since they would otherwise be invisible.
+func PrintPlan(w io.Writer, cmds []DispatchCmd, asJSON bool) error {
+	if asJSON {
+		if cmds == nil {
+			cmds = []DispatchCmd{}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cmds)
+	}
+
+	if len(cmds) == 0 {
+		_, err := fmt.Fprintln(w, "nothing to dispatch")
+		return err
+	}
+
+	for _, cmd := range cmds {
+		parts := []string{cmd.Dispatcher}
+		for _, arg := range cmd.Args {
+			parts = append(parts, quoteIfInvisible(arg))
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// quoteIfInvisible quotes s when it contains characters that don't print, such as zero-width markers.
+func quoteIfInvisible(s string) string {
+	quoted := strconv.Quote(s)
+	if quoted[1:len(quoted)-1] == s && strings.IndexFunc(s, isCodecRune) == -1 {
+		return s
+	}
+
+	// Quote leaves marks that Go considers printable alone, like variation selectors
+	var escaped strings.Builder
+	for _, r := range quoted {
+		if isCodecRune(r) {
+			rq := strconv.QuoteRuneToASCII(r)
+			escaped.WriteString(rq[1 : len(rq)-1])
+			continue
+		}
+
+		escaped.WriteRune(r)
+	}
+
+	return escaped.String()
+}
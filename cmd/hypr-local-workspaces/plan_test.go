@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPlanned_LeavesCompositorUntouched(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	third := sim.AddWorkspace(0, "3\u200b\u200d")
+	window := sim.AddClient(first)
+	sim.AddClient(third)
+	sim.Focus(first)
+
+	plan, err := RunPlanned(NewAction(sim, sim), func(action *Action) error {
+		return action.MoveToWorkspace(context.Background(), 1, false, true)
+	})
+
+	require.NoError(t, err)
+	// The emptied source goes away, so the compaction that follows is part of the plan
+	assert.Equal(t, []DispatchCmd{
+		MoveAddrToWorkspaceCmd("3\u200b\u200d", window),
+		RenameWorkspaceCmd(third, "1\u200b\u200b"),
+	}, plan)
+	assert.Empty(t, sim.Dispatched)
+	assert.Equal(t, []string{"1\u200b\u200b", "3\u200b\u200d"}, sim.WorkspaceNames(0))
+}
+
+func TestRunPlanned_DoesNotPersistMonitorSlots(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	sim.AddClient(first)
+	sim.Focus(first)
+	path := filepath.Join(t.TempDir(), "monitor-slots.json")
+	action := NewAction(sim, sim).WithMonitorSlots(NewMonitorSlots(path, MonitorIdentityName))
+
+	_, err := RunPlanned(action, func(action *Action) error {
+		return action.GoToWorkspace(context.Background(), 1, true)
+	})
+
+	require.NoError(t, err)
+	assert.NoFileExists(t, path)
+}
+
+func TestRunPlanned_PropagatesDecisionErrors(t *testing.T) {
+	hypr := new(mockHyprctl)
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	_, err := RunPlanned(NewAction(hypr, nil), func(action *Action) error {
+		return action.GoToWorkspace(context.Background(), 1, true)
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestPrintPlan_Human(t *testing.T) {
+	var out bytes.Buffer
+
+	err := PrintPlan(&out, []DispatchCmd{
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+		GoToWorkspaceCmd("2\u200b\u200c"),
+	}, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, "renameworkspace 3 \"2\\u200b\\u200c\"\nworkspace \"name:2\\u200b\\u200c\"\n", out.String())
+}
+
+func TestPrintPlan_JSON(t *testing.T) {
+	var out bytes.Buffer
+
+	require.NoError(t, PrintPlan(&out, []DispatchCmd{FocusMonitorCmd(1)}, true))
+	assert.JSONEq(t, `[{"dispatcher":"focusmonitor","args":["1"]}]`, out.String())
+
+	out.Reset()
+	require.NoError(t, PrintPlan(&out, nil, true))
+	assert.JSONEq(t, `[]`, out.String())
+}
+
+func TestPrintPlan_NothingToDo(t *testing.T) {
+	var out bytes.Buffer
+
+	require.NoError(t, PrintPlan(&out, nil, false))
+	assert.Equal(t, "nothing to dispatch\n", out.String())
+}
+
+func TestQuoteIfInvisible(t *testing.T) {
+	assert.Equal(t, "2:web", quoteIfInvisible("2:web"))
+	assert.Equal(t, `"2\u200b\u200c"`, quoteIfInvisible("2\u200b\u200c"))
+	assert.Equal(t, `"2\U000e0030\U000e0031"`, quoteIfInvisible("2\U000E0030\U000E0031"))
+	assert.Equal(t, `"2\ufe00\ufe01"`, quoteIfInvisible("2\ufe00\ufe01"))
+}
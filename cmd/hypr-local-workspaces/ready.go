@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	DefaultReadyWait  = 10 * time.Second
+	readyInitialDelay = 50 * time.Millisecond
+	readyMaxDelay     = time.Second
+)
+
+// errNotReady is returned by readiness probes while Hyprland is reachable but not done setting up.
+var errNotReady = errors.New("hyprland is not ready")
+
+// readyPolicy keeps probing until the surrounding context gives up.
+func readyPolicy() RetryPolicy {
+	return RetryPolicy{InitialDelay: readyInitialDelay, MaxDelay: readyMaxDelay}
+}
+
+// probeSocket checks that the Hyprland socket at path accepts connections.
+func probeSocket(ctx context.Context, path string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// probeCompositor checks that Hyprland reports at least one monitor and one workspace.
+func probeCompositor(ctx context.Context, h hyprctl) error {
+	snap, err := h.GetSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(snap.Monitors) == 0 {
+		return fmt.Errorf("%w: no monitors reported", errNotReady)
+	}
+
+	if len(snap.Workspaces) == 0 {
+		return fmt.Errorf("%w: no workspaces reported", errNotReady)
+	}
+
+	return nil
+}
+
+func isNotReady(err error) bool {
+	return errors.Is(err, errNotReady) || IsTransient(err)
+}
+
+// WaitForHyprland waits up to globals.Wait for Hyprland's socket to accept connections and for the compositor
+// to report monitors and workspaces, then returns clients for it. It is meant for commands that run from
+// exec-once, which can start before Hyprland is fully up.
+func WaitForHyprland(ctx context.Context, globals GlobalFlags) (hyprctl, dispatcher, error) {
+	if globals.Wait <= 0 {
+		return NewRetryingClients(globals)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, globals.Wait)
+	defer cancel()
+
+	path, err := HyprRequestSocketPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = waitReady(waitCtx, func(ctx context.Context) error {
+		return probeSocket(ctx, path)
+	})
+	if err != nil {
+		return nil, nil, notReadyError(ctx, globals.Wait, err)
+	}
+
+	// Only now, so that auto mode picks the socket rather than falling back to hyprctl
+	h, d, err := NewClients(globals.IPC, globals.Timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = waitReady(waitCtx, func(ctx context.Context) error {
+		return probeCompositor(ctx, h)
+	})
+	if err != nil {
+		return nil, nil, notReadyError(ctx, globals.Wait, err)
+	}
+
+	policy := NewRetryPolicy(globals.Retries, globals.RetryDelay)
+	return NewRetryingHyprctl(h, policy), NewRetryingDispatcher(d, policy), nil
+}
+
+// waitReady runs probe until it succeeds, fails for a reason other than Hyprland still starting, or ctx ends.
+func waitReady(ctx context.Context, probe func(ctx context.Context) error) error {
+	return readyPolicy().Do(ctx, isNotReady, probe)
+}
+
+func notReadyError(ctx context.Context, wait time.Duration, err error) error {
+	// Interrupted or out of overall deadline, not a readiness problem
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("hyprland not ready after %s: %w", wait, err)
+	}
+
+	return err
+}
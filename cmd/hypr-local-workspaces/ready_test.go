@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hyprSocketEnv points the Hyprland socket lookup at a temporary directory and returns the request socket path.
+func hyprSocketEnv(t *testing.T) string {
+	t.Helper()
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "sig")
+
+	dir := filepath.Join(runtimeDir, "hypr", "sig")
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+
+	return filepath.Join(dir, hyprRequestSocket)
+}
+
+func TestProbeCompositor_RequiresMonitorsAndWorkspaces(t *testing.T) {
+	hypr := new(mockHyprctl)
+	hypr.On("GetSnapshot").Return(Snapshot{}, nil).Once()
+	hypr.On("GetSnapshot").Return(Snapshot{Monitors: []MonitorDTO{{ID: 0}}}, nil).Once()
+	hypr.On("GetSnapshot").Return(Snapshot{Monitors: []MonitorDTO{{ID: 0}}, Workspaces: []WorkspaceDTO{{ID: 1}}}, nil).Once()
+
+	assert.ErrorIs(t, probeCompositor(context.Background(), hypr), errNotReady)
+	assert.ErrorIs(t, probeCompositor(context.Background(), hypr), errNotReady)
+	assert.NoError(t, probeCompositor(context.Background(), hypr))
+}
+
+func TestWaitForHyprland_WaitsForMonitors(t *testing.T) {
+	path := hyprSocketEnv(t)
+
+	var calls atomic.Int32
+	startFakeHyprSocketAt(t, path, func(string) string {
+		// Monitors show up on the third look
+		if calls.Add(1) < 3 {
+			return `[] [] [] {} {}`
+		}
+
+		return `[{"id":0,"name":"DP-1"}] [{"id":1,"name":"1"}] [] {"id":1} {}`
+	})
+
+	globals := defaultGlobalFlags()
+	globals.IPC = IPCSocket
+	globals.Wait = 5 * time.Second
+
+	hypr, dispatcher, err := WaitForHyprland(context.Background(), globals)
+
+	require.NoError(t, err)
+	assert.NotNil(t, hypr)
+	assert.NotNil(t, dispatcher)
+	assert.GreaterOrEqual(t, calls.Load(), int32(3))
+}
+
+func TestWaitForHyprland_GivesUpAfterWait(t *testing.T) {
+	hyprSocketEnv(t)
+
+	globals := defaultGlobalFlags()
+	globals.Wait = 100 * time.Millisecond
+
+	start := time.Now()
+	_, _, err := WaitForHyprland(context.Background(), globals)
+
+	assert.ErrorContains(t, err, "not ready after 100ms")
+	assert.Equal(t, ExitTimeout, exitCode(err))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestWaitForHyprland_NoWaitSkipsProbes(t *testing.T) {
+	hyprSocketEnv(t)
+
+	globals := defaultGlobalFlags()
+	globals.IPC = IPCHyprctl
+	globals.Wait = 0
+
+	_, _, err := WaitForHyprland(context.Background(), globals)
+
+	assert.NoError(t, err)
+}
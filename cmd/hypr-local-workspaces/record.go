@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// NewRecordingClients wraps h and d so every request they make is written to a new trace file at path, which the
+// returned closer closes. Entries are written unbuffered, so the trace is complete even when the process exits
+// through os.Exit.
+func NewRecordingClients(path string, h hyprctl, d dispatcher) (hyprctl, dispatcher, io.Closer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating trace: %w", err)
+	}
+
+	rec := NewRecorder(f)
+	return &recordingHyprctl{inner: h, rec: rec}, &recordingDispatcher{inner: d, rec: rec}, f, nil
+}
+
+func (r *Recorder) write(entry TraceEntry, err error) error {
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Timeout = errors.Is(err, context.DeadlineExceeded)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if encErr := r.enc.Encode(entry); encErr != nil {
+		return errors.Join(err, fmt.Errorf("writing trace: %w", encErr))
+	}
+
+	return err
+}
+
+// recordQuery runs query and writes its response to the trace, returning the response unchanged.
+func recordQuery[T any](r *Recorder, entry TraceEntry, query func() (T, error)) (T, error) {
+	v, err := query()
+	if err == nil {
+		response, encErr := json.Marshal(v)
+		if encErr != nil {
+			return v, fmt.Errorf("writing trace: %w", encErr)
+		}
+
+		entry.Response = response
+	}
+
+	return v, r.write(entry, err)
+}
+
+func (h *recordingHyprctl) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetMonitors"}, func() ([]MonitorDTO, error) {
+		return h.inner.GetMonitors(ctx)
+	})
+}
+
+func (h *recordingHyprctl) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetWorkspaces"}, func() ([]WorkspaceDTO, error) {
+		return h.inner.GetWorkspaces(ctx)
+	})
+}
+
+func (h *recordingHyprctl) GetClients(ctx context.Context) ([]ClientDTO, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetClients"}, func() ([]ClientDTO, error) {
+		return h.inner.GetClients(ctx)
+	})
+}
+
+func (h *recordingHyprctl) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetClientsInWorkspace", WorkspaceID: workspaceID}, func() ([]ClientDTO, error) {
+		return h.inner.GetClientsInWorkspace(ctx, workspaceID)
+	})
+}
+
+func (h *recordingHyprctl) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetActiveWorkspace"}, func() (WorkspaceDTO, error) {
+		return h.inner.GetActiveWorkspace(ctx)
+	})
+}
+
+func (h *recordingHyprctl) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetActiveWindow"}, func() (ClientDTO, error) {
+		return h.inner.GetActiveWindow(ctx)
+	})
+}
+
+func (h *recordingHyprctl) GetActiveMonitorID(ctx context.Context) (int, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetActiveMonitorID"}, func() (int, error) {
+		return h.inner.GetActiveMonitorID(ctx)
+	})
+}
+
+func (h *recordingHyprctl) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	return recordQuery(h.rec, TraceEntry{Method: "GetSnapshot"}, func() (Snapshot, error) {
+		return h.inner.GetSnapshot(ctx)
+	})
+}
+
+func (d *recordingDispatcher) GoToWorkspace(ctx context.Context, wsName string) error {
+	err := d.inner.GoToWorkspace(ctx, wsName)
+	return d.rec.write(TraceEntry{Method: "GoToWorkspace", Cmds: []DispatchCmd{GoToWorkspaceCmd(wsName)}}, err)
+}
+
+func (d *recordingDispatcher) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	err := d.inner.RenameWorkspace(ctx, id, wsNewName)
+	return d.rec.write(TraceEntry{Method: "RenameWorkspace", Cmds: []DispatchCmd{RenameWorkspaceCmd(id, wsNewName)}}, err)
+}
+
+func (d *recordingDispatcher) FocusMonitor(ctx context.Context, monitorId int) error {
+	err := d.inner.FocusMonitor(ctx, monitorId)
+	return d.rec.write(TraceEntry{Method: "FocusMonitor", Cmds: []DispatchCmd{FocusMonitorCmd(monitorId)}}, err)
+}
+
+func (d *recordingDispatcher) MoveToWorkspace(ctx context.Context, wsName string) error {
+	err := d.inner.MoveToWorkspace(ctx, wsName)
+	return d.rec.write(TraceEntry{Method: "MoveToWorkspace", Cmds: []DispatchCmd{MoveToWorkspaceCmd(wsName)}}, err)
+}
+
+func (d *recordingDispatcher) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	err := d.inner.MoveAddrToWorkspace(ctx, wsName, windowAddr)
+	return d.rec.write(TraceEntry{Method: "MoveAddrToWorkspace", Cmds: []DispatchCmd{MoveAddrToWorkspaceCmd(wsName, windowAddr)}}, err)
+}
+
+func (d *recordingDispatcher) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	err := d.inner.Batch(ctx, cmds)
+	return d.rec.write(TraceEntry{Method: "Batch", Cmds: cmds}, err)
+}
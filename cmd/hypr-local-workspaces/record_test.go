@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay_ReproducesActionDispatches(t *testing.T) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	third := sim.AddWorkspace(0, "3\u200b\u200d")
+	sim.AddClient(first)
+	sim.AddClient(third)
+	sim.Focus(first)
+
+	var trace bytes.Buffer
+	rec := NewRecorder(&trace)
+	recorded := NewAction(&recordingHyprctl{inner: sim, rec: rec}, &recordingDispatcher{inner: sim, rec: rec})
+	require.NoError(t, recorded.GoToWorkspace(context.Background(), 1, true))
+
+	replay, err := NewReplay(&trace)
+	require.NoError(t, err)
+	require.NoError(t, NewAction(replay, replay).GoToWorkspace(context.Background(), 1, true))
+
+	assert.Equal(t, sim.Dispatched, replay.RecordedDispatches())
+	assert.Equal(t, replay.RecordedDispatches(), replay.Dispatched)
+}
+
+var updateTraces = flag.Bool("update", false, "re-record the traces under testdata against the simulator")
+
+// recordMoveAllCompacts records moving both windows off the middle of three workspaces, which leaves a gap for
+// compaction to close.
+func recordMoveAllCompacts(t *testing.T, path string) {
+	sim := newSimulator()
+	sim.AddMonitor("DP-1")
+	first := sim.AddWorkspace(0, "1\u200b\u200b")
+	second := sim.AddWorkspace(0, "2\u200b\u200c")
+	third := sim.AddWorkspace(0, "3\u200b\u200d")
+	sim.AddClient(first)
+	sim.AddClient(second)
+	sim.AddClient(second)
+	sim.AddClient(third)
+	sim.Focus(second)
+
+	h, d, trace, err := NewRecordingClients(path, sim, sim)
+	require.NoError(t, err)
+	defer trace.Close()
+
+	require.NoError(t, NewAction(h, d).MoveToWorkspace(context.Background(), 2, true, true))
+}
+
+func TestReplay_RecordedTrace(t *testing.T) {
+	const path = "testdata/move-all-compacts.jsonl"
+	if *updateTraces {
+		recordMoveAllCompacts(t, path)
+	}
+
+	replay, err := LoadReplay(path)
+	require.NoError(t, err)
+
+	err = NewAction(replay, replay).MoveToWorkspace(context.Background(), 2, true, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		MoveAddrToWorkspaceCmd("3\u200b\u200d", "0xa002"),
+		MoveAddrToWorkspaceCmd("3\u200b\u200d", "0xa003"),
+		RenameWorkspaceCmd(-1339, "2\u200b\u200c"),
+	}, replay.Dispatched)
+}
+
+func TestReplay_ReturnsRecordedErrors(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	hypr.On("GetMonitors").Return([]MonitorDTO(nil), fmt.Errorf("monitors: %w", context.DeadlineExceeded))
+	dispatcher.On("GoToWorkspace", "2").Return(assert.AnError)
+
+	var trace bytes.Buffer
+	rec := NewRecorder(&trace)
+	_, err := (&recordingHyprctl{inner: hypr, rec: rec}).GetMonitors(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	err = (&recordingDispatcher{inner: dispatcher, rec: rec}).GoToWorkspace(context.Background(), "2")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	replay, err := NewReplay(&trace)
+	require.NoError(t, err)
+
+	_, err = replay.GetMonitors(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, ExitTimeout, exitCode(err))
+
+	assert.EqualError(t, replay.GoToWorkspace(context.Background(), "2"), assert.AnError.Error())
+
+	// Nothing left to answer with, and a dispatch past the recording just gets collected
+	_, err = replay.GetMonitors(context.Background())
+	assert.ErrorContains(t, err, "no recorded GetMonitors response left")
+	assert.NoError(t, replay.GoToWorkspace(context.Background(), "3"))
+}
+
+func TestNewReplay_RejectsMalformedTrace(t *testing.T) {
+	_, err := NewReplay(bytes.NewBufferString("{\"method\":\"GetSnapshot\"}\nnot json\n"))
+
+	assert.ErrorContains(t, err, "trace line 2")
+}
+
+func TestNewReplay_AcceptsUntaggedDispatchCmds(t *testing.T) {
+	// Traces recorded before DispatchCmd had JSON tags spell its fields the way Go does
+	replay, err := NewReplay(bytes.NewBufferString(`{"method":"Batch","cmds":[{"Dispatcher":"workspace","Args":["name:2"]}]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{GoToWorkspaceCmd("2")}, replay.RecordedDispatches())
+}
+
+// LoadReplay reads the trace a test replays from a file under testdata.
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewReplay(f)
+}
+
+// Replay implements hyprctl and dispatcher on top of a recorded trace.
+type Replay struct {
+	mu            sync.Mutex
+	queries       map[string][]TraceEntry
+	dispatches    []TraceEntry
+	dispatchCalls int
+	Dispatched    []DispatchCmd
+}
+
+// dispatchMethods are the trace methods that came from the dispatcher rather than from a query.
+var dispatchMethods = map[string]bool{
+	"GoToWorkspace":       true,
+	"RenameWorkspace":     true,
+	"FocusMonitor":        true,
+	"MoveToWorkspace":     true,
+	"MoveAddrToWorkspace": true,
+	"Batch":               true,
+}
+
+// NewReplay reads a trace written by --record. Queries are answered with the recorded responses, in the order
+// they were recorded for each method, while dispatches are collected in Dispatched so a test can compare them
+// with what was recorded. Field names are matched case-insensitively, so traces recorded before DispatchCmd had
+// JSON tags still load.
+func NewReplay(r io.Reader) (*Replay, error) {
+	replay := &Replay{queries: map[string][]TraceEntry{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("trace line %d: %w", line, err)
+		}
+
+		if dispatchMethods[entry.Method] {
+			replay.dispatches = append(replay.dispatches, entry)
+		} else {
+			replay.queries[entry.Method] = append(replay.queries[entry.Method], entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading trace: %w", err)
+	}
+
+	return replay, nil
+}
+
+// RecordedDispatches returns every command the recorded run dispatched, in order.
+func (r *Replay) RecordedDispatches() []DispatchCmd {
+	var cmds []DispatchCmd
+	for _, entry := range r.dispatches {
+		cmds = append(cmds, entry.Cmds...)
+	}
+
+	return cmds
+}
+
+func traceError(entry TraceEntry) error {
+	if entry.Error == "" {
+		return nil
+	}
+
+	return &remoteError{msg: entry.Error, timeout: entry.Timeout}
+}
+
+// replayQuery answers a query with the next recorded response for method.
+func replayQuery[T any](r *Replay, method string) (T, error) {
+	var v T
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := r.queries[method]
+	if len(entries) == 0 {
+		return v, fmt.Errorf("replay: no recorded %s response left", method)
+	}
+
+	entry := entries[0]
+	r.queries[method] = entries[1:]
+
+	if err := traceError(entry); err != nil {
+		return v, err
+	}
+
+	if err := json.Unmarshal(entry.Response, &v); err != nil {
+		return v, fmt.Errorf("replay: decoding %s response: %w", method, err)
+	}
+
+	return v, nil
+}
+
+// replayDispatch collects cmds and fails them the way the matching recorded dispatch did, if any.
+func (r *Replay) replayDispatch(cmds []DispatchCmd) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Dispatched = append(r.Dispatched, cmds...)
+
+	call := r.dispatchCalls
+	r.dispatchCalls++
+
+	// Once the run diverges from the recording there is nothing meaningful to fail with
+	if call >= len(r.dispatches) || !reflect.DeepEqual(r.dispatches[call].Cmds, cmds) {
+		return nil
+	}
+
+	return traceError(r.dispatches[call])
+}
+
+func (r *Replay) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	return replayQuery[[]MonitorDTO](r, "GetMonitors")
+}
+
+func (r *Replay) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
+	return replayQuery[[]WorkspaceDTO](r, "GetWorkspaces")
+}
+
+func (r *Replay) GetClients(ctx context.Context) ([]ClientDTO, error) {
+	return replayQuery[[]ClientDTO](r, "GetClients")
+}
+
+func (r *Replay) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
+	return replayQuery[[]ClientDTO](r, "GetClientsInWorkspace")
+}
+
+func (r *Replay) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
+	return replayQuery[WorkspaceDTO](r, "GetActiveWorkspace")
+}
+
+func (r *Replay) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
+	return replayQuery[ClientDTO](r, "GetActiveWindow")
+}
+
+func (r *Replay) GetActiveMonitorID(ctx context.Context) (int, error) {
+	return replayQuery[int](r, "GetActiveMonitorID")
+}
+
+func (r *Replay) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	return replayQuery[Snapshot](r, "GetSnapshot")
+}
+
+func (r *Replay) GoToWorkspace(ctx context.Context, wsName string) error {
+	return r.replayDispatch([]DispatchCmd{GoToWorkspaceCmd(wsName)})
+}
+
+func (r *Replay) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	return r.replayDispatch([]DispatchCmd{RenameWorkspaceCmd(id, wsNewName)})
+}
+
+func (r *Replay) FocusMonitor(ctx context.Context, monitorId int) error {
+	return r.replayDispatch([]DispatchCmd{FocusMonitorCmd(monitorId)})
+}
+
+func (r *Replay) MoveToWorkspace(ctx context.Context, wsName string) error {
+	return r.replayDispatch([]DispatchCmd{MoveToWorkspaceCmd(wsName)})
+}
+
+func (r *Replay) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	return r.replayDispatch([]DispatchCmd{MoveAddrToWorkspaceCmd(wsName, windowAddr)})
+}
+
+func (r *Replay) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	return r.replayDispatch(cmds)
+}
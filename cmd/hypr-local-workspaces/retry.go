@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultRetries    = 2
+	DefaultRetryDelay = 100 * time.Millisecond
+	retryMaxDelay     = 2 * time.Second
+)
+
+// NewRetryPolicy returns a policy making one attempt plus the given number of retries, with exponential
+// backoff starting at delay.
+func NewRetryPolicy(retries int, delay time.Duration) RetryPolicy {
+	return RetryPolicy{MaxAttempts: retries + 1, InitialDelay: delay, MaxDelay: retryMaxDelay}
+}
+
+// IsTransient reports whether a failed query is worth retrying: Hyprland was not reachable yet, did not
+// answer in time, or hyprctl gave up on it. Cancellation is never transient.
+func IsTransient(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case isConnectError(err), errors.Is(err, errHyprctlFailed), errors.Is(err, context.DeadlineExceeded):
+		return true
+	default:
+		return false
+	}
+}
+
+// isConnectError reports whether err means the request never reached Hyprland. Only those failures are
+// retried for dispatches, since retrying anything else could apply a batch twice.
+func isConnectError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.EAGAIN)
+}
+
+// delay returns how long to wait before the given retry (1-based).
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.InitialDelay
+	for i := 1; i < retry && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	return d
+}
+
+// Do runs op until it succeeds, fails with an error retryable rejects, runs out of attempts or ctx ends.
+// The last error from op is returned, wrapped with the context's error if ctx ended while backing off.
+func (p RetryPolicy) Do(ctx context.Context, retryable func(error) bool, op func(ctx context.Context) error) error {
+	_, err := retryValue(ctx, p, retryable, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	})
+
+	return err
+}
+
+func retryValue[T any](ctx context.Context, p RetryPolicy, retryable func(error) bool, op func(ctx context.Context) (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := op(ctx)
+		if err == nil || !retryable(err) || (p.MaxAttempts > 0 && attempt >= p.MaxAttempts) {
+			return result, err
+		}
+
+		timer := time.NewTimer(p.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// NewRetryingHyprctl wraps h so transient query failures are retried according to policy.
+func NewRetryingHyprctl(h hyprctl, policy RetryPolicy) hyprctl {
+	return &retryingHyprctl{inner: h, policy: policy}
+}
+
+// NewRetryingDispatcher wraps d so dispatches that could not reach Hyprland are retried according to policy.
+func NewRetryingDispatcher(d dispatcher, policy RetryPolicy) dispatcher {
+	return &retryingDispatcher{inner: d, policy: policy}
+}
+
+func (r *retryingHyprctl) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetMonitors)
+}
+
+func (r *retryingHyprctl) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetWorkspaces)
+}
+
+func (r *retryingHyprctl) GetClients(ctx context.Context) ([]ClientDTO, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetClients)
+}
+
+func (r *retryingHyprctl) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
+	return retryValue(ctx, r.policy, IsTransient, func(ctx context.Context) ([]ClientDTO, error) {
+		return r.inner.GetClientsInWorkspace(ctx, workspaceID)
+	})
+}
+
+func (r *retryingHyprctl) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetActiveWorkspace)
+}
+
+func (r *retryingHyprctl) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetActiveWindow)
+}
+
+func (r *retryingHyprctl) GetActiveMonitorID(ctx context.Context) (int, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetActiveMonitorID)
+}
+
+func (r *retryingHyprctl) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	return retryValue(ctx, r.policy, IsTransient, r.inner.GetSnapshot)
+}
+
+func (r *retryingDispatcher) GoToWorkspace(ctx context.Context, wsName string) error {
+	return r.policy.Do(ctx, isConnectError, func(ctx context.Context) error {
+		return r.inner.GoToWorkspace(ctx, wsName)
+	})
+}
+
+func (r *retryingDispatcher) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	return r.policy.Do(ctx, isConnectError, func(ctx context.Context) error {
+		return r.inner.RenameWorkspace(ctx, id, wsNewName)
+	})
+}
+
+func (r *retryingDispatcher) FocusMonitor(ctx context.Context, monitorId int) error {
+	return r.policy.Do(ctx, isConnectError, func(ctx context.Context) error {
+		return r.inner.FocusMonitor(ctx, monitorId)
+	})
+}
+
+func (r *retryingDispatcher) MoveToWorkspace(ctx context.Context, wsName string) error {
+	return r.policy.Do(ctx, isConnectError, func(ctx context.Context) error {
+		return r.inner.MoveToWorkspace(ctx, wsName)
+	})
+}
+
+func (r *retryingDispatcher) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	return r.policy.Do(ctx, isConnectError, func(ctx context.Context) error {
+		return r.inner.MoveAddrToWorkspace(ctx, wsName, windowAddr)
+	})
+}
+
+func (r *retryingDispatcher) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	return r.policy.Do(ctx, isConnectError, func(ctx context.Context) error {
+		return r.inner.Batch(ctx, cmds)
+	})
+}
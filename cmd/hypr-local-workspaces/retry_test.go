@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errRefused = &net.OpError{Op: "dial", Net: "unix", Err: syscall.ECONNREFUSED}
+
+func fastRetries(retries int) RetryPolicy {
+	return NewRetryPolicy(retries, time.Millisecond)
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, IsTransient(errRefused))
+	assert.True(t, IsTransient(fmt.Errorf("%w: hyprctl -j monitors exited with 1", errHyprctlFailed)))
+	assert.True(t, IsTransient(context.DeadlineExceeded))
+	assert.False(t, IsTransient(context.Canceled))
+	assert.False(t, IsTransient(errors.New("invalid character 'H' looking for beginning of value")))
+	assert.False(t, IsTransient(nil))
+}
+
+func TestRetryPolicy_DelayDoublesUpToMax(t *testing.T) {
+	p := RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: 350 * time.Millisecond}
+
+	assert.Equal(t, 100*time.Millisecond, p.delay(1))
+	assert.Equal(t, 200*time.Millisecond, p.delay(2))
+	assert.Equal(t, 350*time.Millisecond, p.delay(3))
+	assert.Equal(t, 350*time.Millisecond, p.delay(10))
+}
+
+func TestRetryingHyprctl_RetriesTransientFailures(t *testing.T) {
+	hypr := new(mockHyprctl)
+	defer hypr.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, errRefused).Twice()
+	hypr.On("GetSnapshot").Return(Snapshot{ActiveWorkspace: WorkspaceDTO{ID: 1}}, nil).Once()
+
+	snap, err := NewRetryingHyprctl(hypr, fastRetries(2)).GetSnapshot(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, snap.ActiveWorkspace.ID)
+}
+
+func TestRetryingHyprctl_GivesUpAfterMaxAttempts(t *testing.T) {
+	hypr := new(mockHyprctl)
+	defer hypr.AssertExpectations(t)
+
+	hypr.On("GetMonitors").Return([]MonitorDTO(nil), errRefused).Times(3)
+
+	_, err := NewRetryingHyprctl(hypr, fastRetries(2)).GetMonitors(context.Background())
+
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+}
+
+func TestRetryingHyprctl_DoesNotRetryPermanentFailures(t *testing.T) {
+	hypr := new(mockHyprctl)
+	defer hypr.AssertExpectations(t)
+
+	hypr.On("GetWorkspaces").Return([]WorkspaceDTO(nil), assert.AnError).Once()
+
+	_, err := NewRetryingHyprctl(hypr, fastRetries(5)).GetWorkspaces(context.Background())
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestRetryingHyprctl_StopsWhenContextEnds(t *testing.T) {
+	hypr := new(mockHyprctl)
+	hypr.On("GetMonitors").Return([]MonitorDTO(nil), errRefused)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	policy := RetryPolicy{InitialDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	_, err := NewRetryingHyprctl(hypr, policy).GetMonitors(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+	assert.Equal(t, ExitInterrupted, exitCode(err))
+}
+
+func TestRetryingDispatcher_OnlyRetriesUnsentRequests(t *testing.T) {
+	dispatcher := new(mockDispatcher)
+	defer dispatcher.AssertExpectations(t)
+
+	cmds := []DispatchCmd{RenameWorkspaceCmd(1, "1")}
+	dispatcher.On("Batch", cmds).Return(errRefused).Once()
+	dispatcher.On("Batch", cmds).Return(nil).Once()
+
+	retrying := NewRetryingDispatcher(dispatcher, fastRetries(2))
+	require.NoError(t, retrying.Batch(context.Background(), cmds))
+
+	// A timeout may mean the batch was applied, so it must not be sent again
+	dispatcher.On("GoToWorkspace", "2").Return(context.DeadlineExceeded).Once()
+	assert.ErrorIs(t, retrying.GoToWorkspace(context.Background(), "2"), context.DeadlineExceeded)
+
+	dispatcher.AssertNumberOfCalls(t, "GoToWorkspace", 1)
+	dispatcher.AssertCalled(t, "Batch", mock.Anything)
+}
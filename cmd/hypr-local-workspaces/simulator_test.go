@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/xKirtle/hypr-local-workspaces/internal/simulator"
+)
+
+var (
+	_ hyprctl    = (*simulator.Simulator)(nil)
+	_ dispatcher = (*simulator.Simulator)(nil)
+)
+
+// newSimulator returns a simulator that orders workspaces the way the CLI does.
+func newSimulator() *simulator.Simulator {
+	return simulator.New(GetSortedWorkspacesOnMonitor)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// snapshotBatch is the batch request that gathers everything a Snapshot holds in one round trip. The replies
+// come back concatenated in the same order.
+const snapshotBatch = "j/monitors;j/workspaces;j/clients;j/activeworkspace;j/activewindow"
+
+// decodeSnapshot decodes the concatenated JSON replies of a snapshotBatch request.
+func decodeSnapshot(out []byte) (Snapshot, error) {
+	var snap Snapshot
+
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	targets := []struct {
+		name string
+		dest any
+	}{
+		{"monitors", &snap.Monitors},
+		{"workspaces", &snap.Workspaces},
+		{"clients", &snap.Clients},
+		{"activeworkspace", &snap.ActiveWorkspace},
+		{"activewindow", &snap.ActiveWindow},
+	}
+
+	for _, target := range targets {
+		if err := decoder.Decode(target.dest); err != nil {
+			return Snapshot{}, fmt.Errorf("decoding %s from snapshot: %w", target.name, err)
+		}
+	}
+
+	return snap, nil
+}
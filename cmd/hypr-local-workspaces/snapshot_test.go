@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSnapshot_DecodesConcatenatedReplies(t *testing.T) {
+	out := []byte(`[{"id":0,"name":"DP-1","x":1920,"y":0,"focused":true}]
+
+[{"id":1,"name":"1\u200b\u200b","monitorID":0,"windows":1},{"id":2,"name":"2\u200b\u200c","monitorID":0,"windows":0}]
+
+[{"address":"0xa","monitor":0,"workspace":{"id":1,"name":"1\u200b\u200b"}}]
+
+{"id":1,"name":"1\u200b\u200b","monitorID":0,"windows":1}
+
+{"address":"0xa","monitor":0,"workspace":{"id":1,"name":"1\u200b\u200b"}}`)
+
+	snap, err := decodeSnapshot(out)
+
+	require.NoError(t, err)
+	assert.Equal(t, []MonitorDTO{{ID: 0, Name: "DP-1", X: 1920, Focused: true}}, snap.Monitors)
+	assert.Len(t, snap.Workspaces, 2)
+	assert.Equal(t, 1, snap.ActiveWorkspace.ID)
+	assert.Equal(t, "0xa", snap.ActiveWindow.Address)
+	assert.Equal(t, snap.Clients, snap.ClientsInWorkspace(1))
+	assert.Empty(t, snap.ClientsInWorkspace(2))
+}
+
+func TestDecodeSnapshot_EmptyActiveWindow(t *testing.T) {
+	out := []byte(`[] [] [] {"id":1,"name":"1\u200b\u200b"} {}`)
+
+	snap, err := decodeSnapshot(out)
+
+	require.NoError(t, err)
+	assert.Equal(t, ClientDTO{}, snap.ActiveWindow)
+}
+
+func TestDecodeSnapshot_TruncatedReplyErrors(t *testing.T) {
+	_, err := decodeSnapshot([]byte(`[] [] []`))
+
+	assert.ErrorContains(t, err, "activeworkspace")
+}
+
+func TestSnapshot_SortedWorkspacesOnMonitor(t *testing.T) {
+	snap := &Snapshot{Workspaces: []WorkspaceDTO{
+		{ID: 3, Name: "2\u200b\u200c", MonitorID: 0},
+		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+	}}
+
+	assert.Equal(t, []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 3, Name: "2\u200b\u200c", MonitorID: 0},
+	}, GetSortedWorkspacesOnMonitor(snap.Workspaces, 0))
+}
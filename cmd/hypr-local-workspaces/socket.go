@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
+)
+
+const (
+	hyprRequestSocket = ".socket.sock"
+)
+
+// HyprSocketDir returns the directory holding the sockets of the running Hyprland instance.
+func HyprSocketDir() (string, error) {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		return "", errors.New("HYPRLAND_INSTANCE_SIGNATURE is not set, is Hyprland running?")
+	}
+
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		dir := filepath.Join(runtimeDir, "hypr", signature)
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+
+	// Hyprland versions before 0.40 kept their sockets under /tmp
+	return filepath.Join("/tmp", "hypr", signature), nil
+}
+
+// HyprRequestSocketPath returns the path of the request socket used by hyprctl.
+func HyprRequestSocketPath() (string, error) {
+	dir, err := HyprSocketDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, hyprRequestSocket), nil
+}
+
+func NewSocketHyprctlClient(path string, timeout time.Duration) hyprctl {
+	return &socketHyprctlClient{path: path, timeout: timeout}
+}
+
+func NewSocketDispatcherClient(path string, timeout time.Duration) dispatcher {
+	return &socketDispatcherClient{path: path, timeout: timeout}
+}
+
+// hyprSocketRequest writes a single request to the Hyprland request socket and reads the full reply.
+// Hyprland closes the connection once it is done writing, so the reply is everything up to EOF. The request
+// is bounded by both timeout and ctx, whichever ends first.
+func hyprSocketRequest(ctx context.Context, path string, timeout time.Duration, request string) ([]byte, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, socketError(ctx, err)
+	}
+
+	defer func(conn net.Conn) {
+		_ = conn.Close()
+	}(conn)
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// Cancellation (SIGINT) unblocks whatever I/O is in flight
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if _, err := io.WriteString(conn, request); err != nil {
+		return nil, socketError(ctx, err)
+	}
+
+	out, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, socketError(ctx, err)
+	}
+
+	return out, nil
+}
+
+// socketError reports why a socket operation was cut short in terms of ctx, so callers can tell timeouts and
+// cancellation apart from other failures regardless of the IPC backend.
+func socketError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
+
+func socketJsonDecode[T any](ctx context.Context, path string, timeout time.Duration, cmd string) (T, error) {
+	out, err := hyprSocketRequest(ctx, path, timeout, "j/"+cmd)
+	if err != nil {
+		var emptyT T
+		return emptyT, err
+	}
+
+	return decodeJson[T](out)
+}
+
+func socketDispatch(ctx context.Context, path string, timeout time.Duration, cmd DispatchCmd) error {
+	request := "dispatch " + cmd.String()
+	out, err := hyprSocketRequest(ctx, path, timeout, request)
+	if err != nil {
+		return err
+	}
+
+	if reply := strings.TrimSpace(string(out)); reply != "ok" {
+		return fmt.Errorf("%s: %s", request, reply)
+	}
+
+	return nil
+}
+
+func (c *socketHyprctlClient) GetMonitors(ctx context.Context) ([]MonitorDTO, error) {
+	return socketJsonDecode[[]MonitorDTO](ctx, c.path, c.timeout, "monitors")
+}
+
+func (c *socketHyprctlClient) GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error) {
+	return socketJsonDecode[[]WorkspaceDTO](ctx, c.path, c.timeout, "workspaces")
+}
+
+func (c *socketHyprctlClient) GetClients(ctx context.Context) ([]ClientDTO, error) {
+	return socketJsonDecode[[]ClientDTO](ctx, c.path, c.timeout, "clients")
+}
+
+func (c *socketHyprctlClient) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error) {
+	clients, err := c.GetClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return hypr.FilterClientsByWorkspace(clients, workspaceID), nil
+}
+
+func (c *socketHyprctlClient) GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error) {
+	return socketJsonDecode[WorkspaceDTO](ctx, c.path, c.timeout, "activeworkspace")
+}
+
+func (c *socketHyprctlClient) GetActiveWindow(ctx context.Context) (ClientDTO, error) {
+	return socketJsonDecode[ClientDTO](ctx, c.path, c.timeout, "activewindow")
+}
+
+func (c *socketHyprctlClient) GetActiveMonitorID(ctx context.Context) (int, error) {
+	activeWs, err := c.GetActiveWorkspace(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	return activeWs.MonitorID, nil
+}
+
+func (c *socketHyprctlClient) GetSnapshot(ctx context.Context) (Snapshot, error) {
+	out, err := hyprSocketRequest(ctx, c.path, c.timeout, "[[BATCH]]"+snapshotBatch)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return decodeSnapshot(out)
+}
+
+func (d *socketDispatcherClient) GoToWorkspace(ctx context.Context, wsName string) error {
+	return socketDispatch(ctx, d.path, d.timeout, GoToWorkspaceCmd(wsName))
+}
+
+func (d *socketDispatcherClient) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	return socketDispatch(ctx, d.path, d.timeout, RenameWorkspaceCmd(id, wsNewName))
+}
+
+func (d *socketDispatcherClient) FocusMonitor(ctx context.Context, monitorId int) error {
+	return socketDispatch(ctx, d.path, d.timeout, FocusMonitorCmd(monitorId))
+}
+
+func (d *socketDispatcherClient) MoveToWorkspace(ctx context.Context, wsName string) error {
+	return socketDispatch(ctx, d.path, d.timeout, MoveToWorkspaceCmd(wsName))
+}
+
+func (d *socketDispatcherClient) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	return socketDispatch(ctx, d.path, d.timeout, MoveAddrToWorkspaceCmd(wsName, windowAddr))
+}
+
+func (d *socketDispatcherClient) Batch(ctx context.Context, cmds []DispatchCmd) error {
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	request := batchRequest(cmds)
+	out, err := hyprSocketRequest(ctx, d.path, d.timeout, "[[BATCH]]"+request)
+	if err != nil {
+		return err
+	}
+
+	return checkBatchReply(request, out)
+}
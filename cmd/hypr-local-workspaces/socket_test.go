@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHyprSocket serves canned replies on a unix socket and records every request it receives.
+type fakeHyprSocket struct {
+	path     string
+	mu       sync.Mutex
+	requests []string
+}
+
+func startFakeHyprSocket(t *testing.T, reply func(request string) string) *fakeHyprSocket {
+	t.Helper()
+
+	return startFakeHyprSocketAt(t, filepath.Join(t.TempDir(), hyprRequestSocket), reply)
+}
+
+func startFakeHyprSocketAt(t *testing.T, path string, reply func(request string) string) *fakeHyprSocket {
+	t.Helper()
+
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	fake := &fakeHyprSocket{path: path}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			buf := make([]byte, 8192)
+			n, _ := conn.Read(buf)
+			request := string(buf[:n])
+
+			fake.mu.Lock()
+			fake.requests = append(fake.requests, request)
+			fake.mu.Unlock()
+
+			_, _ = io.WriteString(conn, reply(request))
+			_ = conn.Close()
+		}
+	}()
+
+	return fake
+}
+
+func (f *fakeHyprSocket) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.requests...)
+}
+
+func TestSocketHyprctlClient_GetWorkspacesUsesJsonPrefix(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string {
+		return `[{"id":1,"name":"1\u200b\u200b","monitorID":0,"windows":2}]`
+	})
+
+	client := NewSocketHyprctlClient(fake.path, time.Second)
+	workspaces, err := client.GetWorkspaces(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []WorkspaceDTO{{ID: 1, Name: "1\u200b\u200b", MonitorID: 0, WindowsCount: 2}}, workspaces)
+	assert.Equal(t, []string{"j/workspaces"}, fake.Requests())
+}
+
+func TestSocketHyprctlClient_GetClientsInWorkspaceFilters(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string {
+		return `[{"address":"0x1","workspace":{"id":3}},{"address":"0x2","workspace":{"id":4}}]`
+	})
+
+	client := NewSocketHyprctlClient(fake.path, time.Second)
+	clients, err := client.GetClientsInWorkspace(context.Background(), 4)
+
+	require.NoError(t, err)
+	assert.Equal(t, []ClientDTO{{Address: "0x2", Workspace: SimpleWorkspace{ID: 4}}}, clients)
+}
+
+func TestSocketHyprctlClient_GetSnapshotIsOneBatchRequest(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string {
+		return "[]\n\n[]\n\n[]\n\n{\"id\":1}\n\n{}"
+	})
+
+	client := NewSocketHyprctlClient(fake.path, time.Second)
+	snap, err := client.GetSnapshot(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, snap.ActiveWorkspace.ID)
+	assert.Equal(t, []string{"[[BATCH]]j/monitors;j/workspaces;j/clients;j/activeworkspace;j/activewindow"}, fake.Requests())
+}
+
+func TestSocketHyprctlClient_InvalidJsonErrors(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string { return "not json" })
+
+	client := NewSocketHyprctlClient(fake.path, time.Second)
+	_, err := client.GetActiveWorkspace(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestSocketHyprctlClient_TimeoutIsDeadlineExceeded(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string {
+		time.Sleep(200 * time.Millisecond)
+		return "[]"
+	})
+
+	client := NewSocketHyprctlClient(fake.path, 20*time.Millisecond)
+	_, err := client.GetMonitors(context.Background())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, ExitTimeout, exitCode(err))
+}
+
+func TestSocketHyprctlClient_CancelledContextInterrupts(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string {
+		time.Sleep(200 * time.Millisecond)
+		return "[]"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	client := NewSocketHyprctlClient(fake.path, time.Second)
+	start := time.Now()
+	_, err := client.GetMonitors(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, ExitInterrupted, exitCode(err))
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestSocketHyprctlClient_MissingSocketErrors(t *testing.T) {
+	client := NewSocketHyprctlClient(filepath.Join(t.TempDir(), "missing.sock"), time.Second)
+	_, err := client.GetMonitors(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestSocketDispatcherClient_WireFormat(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string { return "ok" })
+
+	d := NewSocketDispatcherClient(fake.path, time.Second)
+	require.NoError(t, d.GoToWorkspace(context.Background(), "2\u200b\u200c"))
+	require.NoError(t, d.RenameWorkspace(context.Background(), 7, "1\u200b\u200b"))
+	require.NoError(t, d.FocusMonitor(context.Background(), 1))
+	require.NoError(t, d.MoveToWorkspace(context.Background(), "3\u200b\u200d"))
+	require.NoError(t, d.MoveAddrToWorkspace(context.Background(), "3\u200b\u200d", "0xabc"))
+
+	assert.Equal(t, []string{
+		"dispatch workspace name:2\u200b\u200c",
+		"dispatch renameworkspace 7 1\u200b\u200b",
+		"dispatch focusmonitor 1",
+		"dispatch movetoworkspace 3\u200b\u200d",
+		"dispatch movetoworkspace name:3\u200b\u200d,address:0xabc",
+	}, fake.Requests())
+}
+
+func TestSocketDispatcherClient_ErrorReply(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string { return "Invalid dispatcher" })
+
+	d := NewSocketDispatcherClient(fake.path, time.Second)
+	err := d.FocusMonitor(context.Background(), 3)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid dispatcher")
+}
+
+func TestHyprRequestSocketPath_UsesRuntimeDir(t *testing.T) {
+	runtimeDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(runtimeDir, "hypr", "sig"), 0o755))
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "sig")
+
+	path, err := HyprRequestSocketPath()
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(runtimeDir, "hypr", "sig", hyprRequestSocket), path)
+}
+
+func TestHyprRequestSocketPath_RequiresSignature(t *testing.T) {
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+
+	_, err := HyprRequestSocketPath()
+
+	assert.Error(t, err)
+}
+
+func TestNewClients_AutoFallsBackToHyprctl(t *testing.T) {
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+
+	hypr, d, err := NewClients(IPCAuto, time.Second)
+
+	require.NoError(t, err)
+	assert.IsType(t, &hyprctlClient{}, hypr)
+	assert.IsType(t, &dispatcherClient{}, d)
+}
+
+func TestNewClients_SocketRequiresReachableSocket(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "missing")
+
+	_, _, err := NewClients(IPCSocket, time.Second)
+	assert.Error(t, err)
+
+	_, _, err = NewClients("carrier-pigeon", time.Second)
+	assert.Error(t, err)
+}
+
+func TestSocketDispatcherClient_BatchWireFormat(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string { return "ok\n\nok" })
+
+	d := NewSocketDispatcherClient(fake.path, time.Second)
+	err := d.Batch(context.Background(), []DispatchCmd{
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+		GoToWorkspaceCmd("2\u200b\u200c"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"[[BATCH]]dispatch renameworkspace 3 2\u200b\u200c;dispatch workspace name:2\u200b\u200c",
+	}, fake.Requests())
+}
+
+func TestSocketDispatcherClient_BatchPartialFailure(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string { return "ok\n\nworkspace not found" })
+
+	d := NewSocketDispatcherClient(fake.path, time.Second)
+	err := d.Batch(context.Background(), []DispatchCmd{RenameWorkspaceCmd(3, "a"), RenameWorkspaceCmd(4, "b")})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workspace not found")
+}
+
+func TestSocketDispatcherClient_EmptyBatchIsNoOp(t *testing.T) {
+	fake := startFakeHyprSocket(t, func(string) string { return "ok" })
+
+	d := NewSocketDispatcherClient(fake.path, time.Second)
+
+	require.NoError(t, d.Batch(context.Background(), nil))
+	assert.Empty(t, fake.Requests())
+}
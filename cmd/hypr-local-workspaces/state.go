@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// loadState reads a JSON object persisted by saveState. A missing file is an empty state, what names the state
+// in errors.
+func loadState[T any](path, what string) (map[string]T, error) {
+	state := map[string]T{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", what, err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("decoding %s %s: %w", what, path, err)
+	}
+
+	return state, nil
+}
+
+// updateState loads the state at path, applies update and saves the result if update reports a change. A lock
+// held from loading to saving keeps concurrent processes, like the daemon and a direct command, from losing each
+// other's changes. A state that can't be locked is still loaded and updated, just not saved, and the locking
+// error is returned.
+func updateState[T any](path, what string, update func(state map[string]T) bool) error {
+	unlock, lockErr := lockState(path, what)
+	if lockErr == nil {
+		defer unlock()
+	}
+
+	state, err := loadState[T](path, what)
+	if err != nil {
+		return err
+	}
+
+	if !update(state) || lockErr != nil {
+		return lockErr
+	}
+
+	return saveState(path, what, state)
+}
+
+// lockState takes an exclusive lock on a file next to path, creating its directory if needed.
+func lockState(path, what string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("locking %s: %w", what, err)
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", what, err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking %s: %w", what, err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// saveState writes state through a temporary file of its own, so concurrent readers never see a partial file
+// and concurrent writers never write into each other's.
+func saveState[T any](path, what string, state map[string]T) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing %s: %w", what, err)
+	}
+
+	return nil
+}
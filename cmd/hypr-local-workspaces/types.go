@@ -1,62 +1,255 @@
 package main
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
 
-type MonitorDTO struct {
-	ID              int
-	Name            string
-	Focused         bool
-	ActiveWorkspace SimpleWorkspace
-}
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
+)
 
-type WorkspaceDTO struct {
-	ID           int
-	Name         string
-	Monitor      string
-	MonitorID    int
-	WindowsCount int `json:"windows"`
+// The compositor state and dispatches are shared with the simulator the tests run against.
+type (
+	MonitorDTO      = hypr.MonitorDTO
+	WorkspaceDTO    = hypr.WorkspaceDTO
+	ClientDTO       = hypr.ClientDTO
+	SimpleWorkspace = hypr.SimpleWorkspace
+	Snapshot        = hypr.Snapshot
+	DispatchCmd     = hypr.DispatchCmd
+)
+
+type Action struct {
+	hyprctl       hyprctl
+	dispatcher    dispatcher
+	maxWorkspaces int           // Local workspaces a monitor may grow to, 0 = unlimited
+	slots         *MonitorSlots // Stable monitor slots encoded in names, nil = Hyprland's monitor IDs
+	labels        *Labels       // Visible labels of local workspaces, nil = none
+	codec         Codec         // Encoding of new names, nil = the codec in use on the monitor
+	history       *History      // Previously active local workspaces, nil = not remembered
 }
 
-type ClientDTO struct {
-	Address   string
-	Monitor   int
-	Workspace SimpleWorkspace
+type hyprctl interface {
+	GetMonitors(ctx context.Context) ([]MonitorDTO, error)
+	GetWorkspaces(ctx context.Context) ([]WorkspaceDTO, error)
+	GetClients(ctx context.Context) ([]ClientDTO, error)
+	GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]ClientDTO, error)
+	GetActiveWorkspace(ctx context.Context) (WorkspaceDTO, error)
+	GetActiveWindow(ctx context.Context) (ClientDTO, error)
+	GetActiveMonitorID(ctx context.Context) (int, error)
+	GetSnapshot(ctx context.Context) (Snapshot, error)
 }
 
-type SimpleWorkspace struct {
-	ID   int
-	Name string
+type dispatcher interface {
+	GoToWorkspace(ctx context.Context, wsName string) error
+	RenameWorkspace(ctx context.Context, id int, wsNewName string) error
+	FocusMonitor(ctx context.Context, monitorId int) error
+	MoveToWorkspace(ctx context.Context, wsName string) error
+	MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error
+	Batch(ctx context.Context, cmds []DispatchCmd) error
 }
 
-type Action struct {
-	hyprctl    hyprctl
-	dispatcher dispatcher
+type hyprctlClient struct {
+	timeout time.Duration
 }
 
-type hyprctl interface {
-	GetMonitors() ([]MonitorDTO, error)
-	GetWorkspaces() ([]WorkspaceDTO, error)
-	GetClients() ([]ClientDTO, error)
-	GetClientsInWorkspace(workspaceID int) ([]ClientDTO, error)
-	GetActiveWorkspace() (WorkspaceDTO, error)
-	GetActiveWindow() (ClientDTO, error)
-	GetActiveMonitorID() (int, error)
+type dispatcherClient struct {
+	timeout time.Duration
 }
 
-type dispatcher interface {
-	GoToWorkspace(wsName string) error
-	RenameWorkspace(id int, wsNewName string) error
-	FocusMonitor(monitorId int) error
-	MoveToWorkspace(wsName string) error
-	MoveAddrToWorkspace(wsName, windowAddr string) error
+type socketHyprctlClient struct {
+	path    string
+	timeout time.Duration
 }
 
-type hyprctlClient struct {
+type socketDispatcherClient struct {
+	path    string
 	timeout time.Duration
 }
 
-type dispatcherClient struct{}
+type EventListener struct {
+	path          string
+	minRetryDelay time.Duration
+	maxRetryDelay time.Duration
+}
+
+type Daemon struct {
+	mu     sync.Mutex
+	action *Action
+	model  *Model
+	events <-chan Event
+	errOut io.Writer
+}
+
+type Model struct {
+	mu             sync.Mutex
+	source         hyprctl
+	monitors       []MonitorDTO
+	workspaces     map[int]WorkspaceDTO
+	clients        map[string]ClientDTO
+	activeWindow   string
+	focusedMonitor string
+	stale          bool
+	dispatched     bool
+	lastActivity   time.Time
+}
+
+// ActionCommand is a parsed goto, move or cycle invocation, runnable directly or inside the daemon.
+type ActionCommand struct {
+	Name         string
+	Index        int
+	All          bool
+	Direction    string
+	Monitor      string // Monitor the command targets, as FindTargetMonitor resolves it, "" = focused
+	BackAndForth bool   // goto on the active workspace goes back to the previous one
+	Globals      GlobalFlags
+}
 
 type GlobalFlags struct {
-	Compact bool
+	Compact         bool
+	IPC             string
+	UseDaemon       bool
+	Timeout         time.Duration // Per request to Hyprland
+	Deadline        time.Duration // For the whole invocation, 0 = none
+	Retries         int           // Extra attempts for requests that failed transiently
+	RetryDelay      time.Duration // First backoff delay, doubled on every retry
+	Wait            time.Duration // How long init and the daemon wait for Hyprland to come up, 0 = don't wait
+	Record          string        // Trace file every request to Hyprland is written to, "" = none
+	DryRun          bool          // Print the planned dispatches instead of running them
+	JSON            bool          // Print output as JSON
+	MaxWorkspaces   int           // Local workspaces a monitor may grow to, 0 = unlimited
+	MonitorIdentity string        // What identifies a monitor across hotplugs: description, name or id
+	Labels          *Labels       // Visible labels of local workspaces, nil = none given
+	Codec           Codec         // Encoding of new names, nil = none given
+	DirectOnly      bool          // A flag the daemon's own clients and slots can't honour was given
+}
+
+// Codec hides a monitor slot and a local workspace index in the invisible suffix of a workspace name.
+type Codec interface {
+	Name() string
+	Encode(slot, index int) (string, error)
+	Decode(suffix string) (slot, index int, err error)
+	Contains(r rune) bool // Whether r belongs to the codec's alphabet
+}
+
+// digitCodec writes numbers with one invisible rune per decimal digit. Slots 0..9 take a single rune, larger
+// ones are bracketed by delimiter, and the index follows.
+type digitCodec struct {
+	name      string
+	digits    []rune
+	delimiter rune
+}
+
+// Naming is how the names of local workspaces on one monitor are built.
+type Naming struct {
+	Codec  Codec          // nil = DefaultCodec
+	Slot   int            // Monitor slot the names encode
+	Labels map[int]string // Visible labels by index, nil = none
+}
+
+// NameProblem is an inconsistency in the names of the workspaces on a monitor, found by verify.
+type NameProblem struct {
+	Kind        string `json:"kind"`
+	WorkspaceID int    `json:"workspaceId"`
+	Name        string `json:"name"`
+	Monitor     string `json:"monitor"`
+	Detail      string `json:"detail"`
+}
+
+// DecodedName is what a local workspace name encodes, in a form scripts can read.
+type DecodedName struct {
+	Name     string `json:"name"`
+	Codec    string `json:"codec"`
+	Monitor  int    `json:"monitor"`  // Monitor slot
+	Position int    `json:"position"` // 1-based, as passed to goto
+	Label    string `json:"label,omitempty"`
+}
+
+// WorkspaceRename is a workspace init --adopt renamed, with the names it had before and has now.
+type WorkspaceRename struct {
+	WorkspaceID int    `json:"workspaceId"`
+	Monitor     string `json:"monitor"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+}
+
+// Labels are the user's visible labels of local workspaces, by 1-based position as shown in the name. Labels
+// given for a monitor, by connector name or description, take precedence over the ones for every monitor.
+type Labels struct {
+	all      map[int]string
+	monitors map[string]map[int]string
+}
+
+// History remembers the local workspace each monitor, by MonitorSlots identity, showed before the last switch
+// made by the tool. Workspaces are tracked by ID, which survives the renames of compaction.
+type History struct {
+	mu       sync.Mutex
+	path     string
+	readOnly bool // Set for --dry-run, which must not change what back returns to
+}
+
+// PreviousWorkspace is a local workspace a monitor showed before, with its index at the time in case Hyprland
+// destroyed it since for being empty.
+type PreviousWorkspace struct {
+	ID    int `json:"id"`
+	Index int `json:"index"`
+}
+
+// MonitorSlots persists which slot each monitor identity encodes in workspace names, so a monitor keeps its
+// workspaces when Hyprland hands it a different ID after a hotplug.
+type MonitorSlots struct {
+	mu       sync.Mutex
+	path     string
+	identity string
+	readOnly bool // Set for --dry-run, which must not assign slots for good
+}
+
+// RetryPolicy bounds how often and how fast a failed request is retried.
+type RetryPolicy struct {
+	MaxAttempts  int // 0 = until the context ends
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+type retryingHyprctl struct {
+	inner  hyprctl
+	policy RetryPolicy
+}
+
+type retryingDispatcher struct {
+	inner  dispatcher
+	policy RetryPolicy
+}
+
+// TraceEntry is one request to Hyprland written by --record: a query with its response, or a dispatch.
+type TraceEntry struct {
+	Method      string          `json:"method"`
+	WorkspaceID int             `json:"workspaceId,omitempty"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	Cmds        []DispatchCmd   `json:"cmds,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	Timeout     bool            `json:"timeout,omitempty"`
+}
+
+// Recorder appends TraceEntry values to a trace, one JSON object per line.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// planDispatcher records dispatches instead of sending them, for --dry-run.
+type planDispatcher struct {
+	mu   sync.Mutex
+	cmds []DispatchCmd
+}
+
+type recordingHyprctl struct {
+	inner hyprctl
+	rec   *Recorder
+}
+
+type recordingDispatcher struct {
+	inner dispatcher
+	rec   *Recorder
 }
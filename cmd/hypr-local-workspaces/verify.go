@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	ProblemForeign   = "foreign"   // Not a local workspace name at all
+	ProblemMisplaced = "misplaced" // Encodes another monitor than the one the workspace is on
+	ProblemDuplicate = "duplicate" // Shares its position with another workspace on the monitor
+	ProblemGap       = "gap"       // Positions before it are missing
+)
+
+// DecodeWorkspaceName returns the monitor slot and the index a local workspace name encodes, in any codec.
+func DecodeWorkspaceName(name string) (int, int, error) {
+	_, slot, index, err := decodeWorkspaceName(name)
+	return slot, index, err
+}
+
+// classifyWorkspacesOnMonitor splits the workspaces on a monitor into the ones whose names belong to it, in
+// local index order, and the rest in ID order. Special workspaces are left out.
+func classifyWorkspacesOnMonitor(workspaces []WorkspaceDTO, monitorID int, naming Naming) ([]WorkspaceDTO, []WorkspaceDTO) {
+	var local, others []WorkspaceDTO
+	for _, ws := range GetWorkspacesOnMonitor(workspaces, monitorID) {
+		if IsSpecialWorkspace(ws) {
+			continue
+		}
+
+		slot, _, err := DecodeWorkspaceName(ws.Name)
+		if err != nil || slot != naming.Slot {
+			others = append(others, ws)
+			continue
+		}
+
+		local = append(local, ws)
+	}
+
+	sort.SliceStable(local, func(i, j int) bool {
+		_, indexI, _ := DecodeWorkspaceName(local[i].Name)
+		_, indexJ, _ := DecodeWorkspaceName(local[j].Name)
+		if indexI != indexJ {
+			return indexI < indexJ
+		}
+
+		return local[i].ID < local[j].ID
+	})
+
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].ID < others[j].ID
+	})
+
+	return local, others
+}
+
+// FindNameProblems checks the workspace names on every monitor against how namings builds them.
+func FindNameProblems(workspaces []WorkspaceDTO, monitors []MonitorDTO, namings map[int]Naming) []NameProblem {
+	var problems []NameProblem
+	for _, mon := range monitors {
+		naming := namingOf(namings, mon.ID)
+		local, others := classifyWorkspacesOnMonitor(workspaces, mon.ID, naming)
+
+		problem := func(kind string, ws WorkspaceDTO, format string, args ...any) {
+			problems = append(problems, NameProblem{
+				Kind:        kind,
+				WorkspaceID: ws.ID,
+				Name:        ws.Name,
+				Monitor:     mon.Name,
+				Detail:      fmt.Sprintf(format, args...),
+			})
+		}
+
+		expected := 0
+		for i, ws := range local {
+			_, index, _ := DecodeWorkspaceName(ws.Name)
+
+			switch {
+			case i > 0 && index == expected-1:
+				problem(ProblemDuplicate, ws, "position %d is also taken by workspace %d", index+1, local[i-1].ID)
+			case index > expected:
+				problem(ProblemGap, ws, "nothing at %s before position %d", positionRange(expected, index-1), index+1)
+				expected = index + 1
+			default:
+				expected++
+			}
+		}
+
+		for _, ws := range others {
+			slot, _, err := DecodeWorkspaceName(ws.Name)
+			if err != nil {
+				problem(ProblemForeign, ws, "not a local workspace name")
+				continue
+			}
+
+			problem(ProblemMisplaced, ws, "named for monitor slot %d, but the monitor is slot %d", slot, naming.Slot)
+		}
+	}
+
+	return problems
+}
+
+// positionRange describes the local indexes from..to as the positions users see.
+func positionRange(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("position %d", from+1)
+	}
+
+	return fmt.Sprintf("positions %d-%d", from+1, to+1)
+}
+
+// GetRepairCmds returns the renames that fix every problem FindNameProblems reports. On each monitor the
+// workspaces named for it keep their order, duplicates included, and everything else is appended in ID order,
+// the way the daemon adopts workspaces. Every monitor is planned in one go by GetRenameCmds, since a workspace
+// may need the name one on another monitor holds, as when two monitors swapped slots.
+func GetRepairCmds(workspaces []WorkspaceDTO, monitors []MonitorDTO, namings map[int]Naming) ([]DispatchCmd, error) {
+	var current []WorkspaceDTO
+	newNames := map[int]string{}
+	for _, mon := range monitors {
+		naming := namingOf(namings, mon.ID)
+		local, others := classifyWorkspacesOnMonitor(workspaces, mon.ID, naming)
+
+		for i, ws := range append(local, others...) {
+			newName, err := naming.Rename(ws.Name, i)
+			if err != nil {
+				return nil, err
+			}
+
+			current = append(current, ws)
+			newNames[ws.ID] = newName
+		}
+	}
+
+	return GetRenameCmds(current, newNames), nil
+}
+
+// VerifyWorkspaces returns the naming problems on every monitor. With repair, it also renames workspaces to fix them.
+func (a *Action) VerifyWorkspaces(ctx context.Context, repair bool) ([]NameProblem, error) {
+	snap, err := a.hyprctl.GetSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	namings, err := a.monitorNamings(snap.Monitors, snap.Workspaces)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := FindNameProblems(snap.Workspaces, snap.Monitors, namings)
+	if !repair || len(problems) == 0 {
+		return problems, nil
+	}
+
+	cmds, err := GetRepairCmds(snap.Workspaces, snap.Monitors, namings)
+	if err != nil {
+		return problems, err
+	}
+
+	return problems, dispatchBatch(ctx, a.dispatcher, cmds)
+}
+
+// PrintProblems writes problems one per line, or as a JSON array.
+func PrintProblems(w io.Writer, problems []NameProblem, asJSON bool) error {
+	if asJSON {
+		if problems == nil {
+			problems = []NameProblem{}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(problems)
+	}
+
+	if len(problems) == 0 {
+		_, err := fmt.Fprintln(w, "no problems found")
+		return err
+	}
+
+	for _, p := range problems {
+		if _, err := fmt.Fprintf(w, "%s: workspace %d %s on %s: %s\n", p.Kind, p.WorkspaceID, quoteIfInvisible(p.Name), p.Monitor, p.Detail); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeWorkspaceName(t *testing.T) {
+	slot, index, err := DecodeWorkspaceName("3:web\u200c\u200d")
+	require.NoError(t, err)
+	assert.Equal(t, 1, slot)
+	assert.Equal(t, 2, index)
+
+	slot, index, err = DecodeWorkspaceName("1\U000E007F\U000E0031\U000E0032\U000E007F\U000E0030")
+	require.NoError(t, err)
+	assert.Equal(t, 12, slot)
+	assert.Equal(t, 0, index)
+
+	_, _, err = DecodeWorkspaceName("web")
+	assert.Error(t, err)
+}
+
+func TestFindNameProblems(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		{ID: 3, Name: "2:web\u200b\u200c", MonitorID: 0},
+		{ID: 4, Name: "5\u200b\u200f", MonitorID: 0},
+		{ID: 5, Name: "1\u200c\u200b", MonitorID: 0},
+		{ID: 6, Name: "scratch", MonitorID: 0},
+		{ID: -98, Name: "special:scratch", MonitorID: 0},
+		{ID: 7, Name: "2\u200c\u200c", MonitorID: 1},
+	}
+
+	problems := FindNameProblems(workspaces, monitors, nil)
+
+	assert.Equal(t, []NameProblem{
+		{Kind: ProblemDuplicate, WorkspaceID: 3, Name: "2:web\u200b\u200c", Monitor: "DP-1", Detail: "position 2 is also taken by workspace 2"},
+		{Kind: ProblemGap, WorkspaceID: 4, Name: "5\u200b\u200f", Monitor: "DP-1", Detail: "nothing at positions 3-4 before position 5"},
+		{Kind: ProblemMisplaced, WorkspaceID: 5, Name: "1\u200c\u200b", Monitor: "DP-1", Detail: "named for monitor slot 1, but the monitor is slot 0"},
+		{Kind: ProblemForeign, WorkspaceID: 6, Name: "scratch", Monitor: "DP-1", Detail: "not a local workspace name"},
+		{Kind: ProblemGap, WorkspaceID: 7, Name: "2\u200c\u200c", Monitor: "HDMI-A-1", Detail: "nothing at position 1 before position 2"},
+	}, problems)
+}
+
+func TestFindNameProblems_UsesSlots(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200e\u200b", MonitorID: 0},
+		{ID: 2, Name: "2\u200e\u200c", MonitorID: 0},
+	}
+
+	assert.Empty(t, FindNameProblems(workspaces, monitors, map[int]Naming{0: {Slot: 3}}))
+	assert.Len(t, FindNameProblems(workspaces, monitors, nil), 2)
+}
+
+func TestGetRepairCmds(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+		{ID: 3, Name: "2\u200b\u200c", MonitorID: 0},
+		{ID: 4, Name: "1\u200c\u200b", MonitorID: 0},
+		{ID: 5, Name: "scratch", MonitorID: 0},
+		{ID: 6, Name: "1\u200c\u200b", MonitorID: 1},
+	}
+
+	cmds, err := GetRepairCmds(workspaces, monitors, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(3, "3\u200b\u200d"),
+		RenameWorkspaceCmd(4, "4\u200b\u200e"),
+		RenameWorkspaceCmd(5, "5\u200b\u200f"),
+	}, cmds)
+}
+
+func TestGetRepairCmds_MonitorsSwappedNames(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}, {ID: 1, Name: "HDMI-A-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200c\u200b", MonitorID: 0},
+		{ID: 2, Name: "1\u200b\u200b", MonitorID: 1},
+	}
+
+	cmds, err := GetRepairCmds(workspaces, monitors, nil)
+
+	// Each wants the name the other holds, so the first steps aside until the second has moved on
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(1, TemporaryWorkspaceName(1)),
+		RenameWorkspaceCmd(2, "1\u200c\u200b"),
+		RenameWorkspaceCmd(1, "1\u200b\u200b"),
+	}, cmds)
+}
+
+func TestGetRepairCmds_DuplicateWaitsForTheNameItTakes(t *testing.T) {
+	monitors := []MonitorDTO{{ID: 0, Name: "DP-1"}}
+	workspaces := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 3, Name: "2\u200b\u200c", MonitorID: 0},
+	}
+
+	cmds, err := GetRepairCmds(workspaces, monitors, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(3, "3\u200b\u200d"),
+		RenameWorkspaceCmd(2, "2\u200b\u200c"),
+	}, cmds)
+}
+
+func TestVerifyWorkspaces_GetSnapshotError(t *testing.T) {
+	hypr := new(mockHyprctl)
+	dispatcher := new(mockDispatcher)
+	defer hypr.AssertExpectations(t)
+	defer dispatcher.AssertExpectations(t)
+
+	hypr.On("GetSnapshot").Return(Snapshot{}, assert.AnError)
+
+	_, err := NewAction(hypr, dispatcher).VerifyWorkspaces(context.Background(), true)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestVerifyWorkspaces_Simulated_Repair(t *testing.T) {
+	sim := simulatedMonitorWith(t, 3)
+	misplaced, err := GetZeroWidthNameFromIndex(1, 0)
+	require.NoError(t, err)
+	sim.AddClient(sim.AddWorkspace(0, misplaced))
+
+	action := NewAction(sim, sim)
+	sim.Dispatched = nil
+	problems, err := action.VerifyWorkspaces(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, ProblemMisplaced, problems[0].Kind)
+	assert.Empty(t, sim.Dispatched, "verify alone changes nothing")
+
+	problems, err = action.VerifyWorkspaces(context.Background(), true)
+	require.NoError(t, err)
+	assert.Len(t, problems, 1, "the problems found before repairing are reported")
+	assert.Equal(t, "4\u200b\u200e", sim.WorkspaceNames(0)[3])
+
+	problems, err = action.VerifyWorkspaces(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestPrintProblems(t *testing.T) {
+	problems := []NameProblem{
+		{Kind: ProblemMisplaced, WorkspaceID: 4, Name: "1\u200c\u200b", Monitor: "DP-1", Detail: "named for monitor slot 1, but the monitor is slot 0"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, PrintProblems(&out, problems, false))
+	assert.Equal(t, "misplaced: workspace 4 \"1\\u200c\\u200b\" on DP-1: named for monitor slot 1, but the monitor is slot 0\n", out.String())
+
+	out.Reset()
+	require.NoError(t, PrintProblems(&out, nil, false))
+	assert.Equal(t, "no problems found\n", out.String())
+
+	out.Reset()
+	require.NoError(t, PrintProblems(&out, nil, true))
+	assert.Equal(t, "[]\n", out.String())
+
+	out.Reset()
+	require.NoError(t, PrintProblems(&out, problems, true))
+	assert.Contains(t, out.String(), `"kind": "misplaced"`)
+	assert.Contains(t, out.String(), `"workspaceId": 4`)
+}
@@ -1,13 +1,14 @@
 package main
 
-import "sort"
-
-func GetWorkspacesOnMonitor(hyprctl hyprctl, monitorId int) ([]WorkspaceDTO, error) {
-	workspaces, err := hyprctl.GetWorkspaces()
-	if err != nil {
-		return nil, err
-	}
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
 
+func GetWorkspacesOnMonitor(workspaces []WorkspaceDTO, monitorId int) []WorkspaceDTO {
 	var monitorWorkspaces []WorkspaceDTO
 	for _, ws := range workspaces {
 		if ws.MonitorID == monitorId {
@@ -15,14 +16,11 @@ func GetWorkspacesOnMonitor(hyprctl hyprctl, monitorId int) ([]WorkspaceDTO, err
 		}
 	}
 
-	return monitorWorkspaces, nil
+	return monitorWorkspaces
 }
 
-func GetSortedWorkspacesOnMonitor(hyprctl hyprctl, monitorId int) ([]WorkspaceDTO, error) {
-	workspaces, err := GetWorkspacesOnMonitor(hyprctl, monitorId)
-	if err != nil {
-		return nil, err
-	}
+func GetSortedWorkspacesOnMonitor(allWorkspaces []WorkspaceDTO, monitorId int) []WorkspaceDTO {
+	workspaces := GetWorkspacesOnMonitor(allWorkspaces, monitorId)
 
 	// Sort by name, ignoring zero-width chars
 	sort.Slice(workspaces, func(i, j int) bool {
@@ -38,7 +36,7 @@ func GetSortedWorkspacesOnMonitor(hyprctl hyprctl, monitorId int) ([]WorkspaceDT
 		return nameI < nameJ
 	})
 
-	return workspaces, nil
+	return workspaces
 }
 
 func DecideTargetWorkspaceIndex(currentIndex, targetIndex int, sortedWorkspaces []WorkspaceDTO) (int, bool) {
@@ -90,6 +88,17 @@ func DecideTargetWorkspaceIndex(currentIndex, targetIndex int, sortedWorkspaces
 	return targetIndex, compact
 }
 
+// LimitTargetWorkspaceIndex keeps targetIndex from creating a new workspace on a monitor that already has
+// maxWorkspaces (0 = unlimited) of its n local workspaces, by clamping it to the last existing one. Existing
+// workspaces past the limit stay reachable.
+func LimitTargetWorkspaceIndex(targetIndex, n, maxWorkspaces int) int {
+	if maxWorkspaces > 0 && n > 0 && targetIndex >= n && n >= maxWorkspaces {
+		return n - 1
+	}
+
+	return targetIndex
+}
+
 func GetWorkspaceIndexOnList(sortedLocalWs []WorkspaceDTO, workspaceID int) int {
 	for i, ws := range sortedLocalWs {
 		if ws.ID == workspaceID {
@@ -100,46 +109,399 @@ func GetWorkspaceIndexOnList(sortedLocalWs []WorkspaceDTO, workspaceID int) int
 	return -1
 }
 
-// TODO: Make variant that accepts a list of workspaces instead of fetching them itself.
-// Most of the time, the caller already has the list of workspaces.
-func CompactLocalWorkspacesOnMonitor(action *Action, monitorID int, fixNames bool) error {
-	hyprctl, dispatcher := action.hyprctl, action.dispatcher
+func CompactLocalWorkspacesOnMonitor(ctx context.Context, action *Action, snap *Snapshot, monitorID int, fixNames bool) error {
+	naming, err := action.monitorNaming(snap.Monitors, snap.Workspaces, monitorID)
+	if err != nil {
+		return err
+	}
 
-	sortedLocalWs, err := GetSortedWorkspacesOnMonitor(hyprctl, monitorID)
+	cmds, err := GetCompactionCmds(GetSortedWorkspacesOnMonitor(snap.Workspaces, monitorID), naming, fixNames)
 	if err != nil {
 		return err
 	}
 
-	for i, ws := range sortedLocalWs {
-		wsIndex, err := GetZeroWidthNameToIndex(ws.Name)
-		if err != nil {
-			if !fixNames {
-				return err
-			}
-		}
+	// All renames land in a single batch so bars never observe a half-compacted monitor
+	return dispatchBatch(ctx, action.dispatcher, cmds)
+}
 
-		if err == nil && wsIndex == i {
-			continue
+// GetCompactionCmds returns the renames needed to make the sorted local workspaces of a monitor contiguous, in the order they must be applied.
+// naming is how the monitor's names are built. Names that encode another monitor, carry an outdated label or were
+// written by another codec are renamed too, so every name ends up exactly what naming would call it. Foreign names
+// are an error, unless fixNames is set and they are renamed as well.
+func GetCompactionCmds(sortedLocalWs []WorkspaceDTO, naming Naming, fixNames bool) ([]DispatchCmd, error) {
+	var cmds []DispatchCmd
+	for i, ws := range sortedLocalWs {
+		if _, err := GetZeroWidthNameToIndex(ws.Name); err != nil && !fixNames {
+			return nil, err
 		}
 
-		newName, err := GetZeroWidthNameFromIndex(monitorID, i)
+		newName, err := naming.Rename(ws.Name, i)
 
 		// Can't really happen? monitorID or index i would have to be out of range
 		// However, monitorID is also checked when fetching sortedLocalWs above
 		// So really only index i would have to be out of range, which is impossible in this loop?
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Should never happen either
 		if ws.Name == newName {
 			continue
 		}
 
-		if err := dispatcher.RenameWorkspace(ws.ID, newName); err != nil {
-			return err
+		cmds = append(cmds, RenameWorkspaceCmd(ws.ID, newName))
+	}
+
+	return cmds, nil
+}
+
+// GetSwapCmds returns the renames that exchange the positions of the workspaces at index and otherIndex of
+// sortedLocalWs, as GetReorderCmds plans them.
+func GetSwapCmds(sortedLocalWs []WorkspaceDTO, naming Naming, index, otherIndex int, compact bool) ([]DispatchCmd, error) {
+	swapped := append([]WorkspaceDTO{}, sortedLocalWs...)
+	swapped[index], swapped[otherIndex] = swapped[otherIndex], swapped[index]
+
+	return GetReorderCmds(sortedLocalWs, swapped, naming, compact)
+}
+
+// GetMoveWorkspaceCmds returns the renames that move the workspace at index of sortedLocalWs to targetIndex,
+// shifting the ones in between by one position, as GetReorderCmds plans them.
+func GetMoveWorkspaceCmds(sortedLocalWs []WorkspaceDTO, naming Naming, index, targetIndex int, compact bool) ([]DispatchCmd, error) {
+	moved := make([]WorkspaceDTO, 0, len(sortedLocalWs))
+	moved = append(moved, sortedLocalWs[:index]...)
+	moved = append(moved, sortedLocalWs[index+1:]...)
+	moved = append(moved[:targetIndex], append([]WorkspaceDTO{sortedLocalWs[index]}, moved[targetIndex:]...)...)
+
+	return GetReorderCmds(sortedLocalWs, moved, naming, compact)
+}
+
+// GetMoveToMonitorCmds returns the renames that let the workspace at index of sourceLocalWs move to another
+// monitor: it is re-encoded for the target monitor at targetIndex (clamped to the end), the target monitor's
+// workspaces from there on shift up, and with compact the source monitor closes the gap it leaves. It also returns
+// the workspace's new name.
+func GetMoveToMonitorCmds(sourceLocalWs []WorkspaceDTO, sourceNaming Naming, index int, targetLocalWs []WorkspaceDTO, targetNaming Naming, targetIndex int, compact bool) ([]DispatchCmd, string, error) {
+	moving := sourceLocalWs[index]
+	targetIndex = min(targetIndex, len(targetLocalWs))
+
+	current := append(append([]WorkspaceDTO{}, targetLocalWs...), moving)
+	inserted := make([]WorkspaceDTO, 0, len(current))
+	inserted = append(inserted, targetLocalWs[:targetIndex]...)
+	inserted = append(inserted, moving)
+	inserted = append(inserted, targetLocalWs[targetIndex:]...)
+
+	cmds, err := GetReorderCmds(current, inserted, targetNaming, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	newName, err := targetNaming.Rename(moving.Name, targetIndex)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !compact {
+		return cmds, newName, nil
+	}
+
+	// The workspace already left its name behind, so the next one can shift into it
+	remaining := make([]WorkspaceDTO, 0, len(sourceLocalWs)-1)
+	remaining = append(remaining, sourceLocalWs[:index]...)
+	remaining = append(remaining, sourceLocalWs[index+1:]...)
+
+	sourceCmds, err := GetCompactionCmds(remaining, sourceNaming, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append(cmds, sourceCmds...), newName, nil
+}
+
+// GetReorderCmds returns the renames that put the workspaces of sortedLocalWs in the order of reordered. With
+// compact, the workspace at position i is named what naming calls i, otherwise it takes the name the workspace at i
+// had before. The renames are ordered by GetRenameCmds.
+func GetReorderCmds(sortedLocalWs, reordered []WorkspaceDTO, naming Naming, compact bool) ([]DispatchCmd, error) {
+	newNames := map[int]string{}
+	for i, ws := range reordered {
+		newName := sortedLocalWs[i].Name
+		if compact {
+			var err error
+			if newName, err = naming.Rename(ws.Name, i); err != nil {
+				return nil, err
+			}
+		}
+
+		newNames[ws.ID] = newName
+	}
+
+	return GetRenameCmds(sortedLocalWs, newNames), nil
+}
+
+// GetRenameCmds returns the renames that give the workspaces in current the names in newNames, by workspace ID.
+// Workspaces missing from newNames keep theirs. Renames are ordered so that no two workspaces share a name
+// mid-batch, and when every remaining rename waits on another one, a workspace steps aside to a temporary name first.
+func GetRenameCmds(current []WorkspaceDTO, newNames map[int]string) []DispatchCmd {
+	type rename struct {
+		ws      WorkspaceDTO
+		newName string
+	}
+
+	taken := map[string]int{}
+	for _, ws := range current {
+		taken[ws.Name]++
+	}
+
+	// In current order, so a swap steps the first of the two aside
+	var pending []rename
+	for _, ws := range current {
+		if newName, ok := newNames[ws.ID]; ok && ws.Name != newName {
+			pending = append(pending, rename{ws: ws, newName: newName})
+		}
+	}
+
+	var cmds []DispatchCmd
+	apply := func(r *rename, newName string) {
+		cmds = append(cmds, RenameWorkspaceCmd(r.ws.ID, newName))
+		taken[r.ws.Name]--
+		taken[newName]++
+		r.ws.Name = newName
+	}
+
+	for len(pending) > 0 {
+		next := -1
+		for i, r := range pending {
+			if taken[r.newName] == 0 {
+				next = i
+				break
+			}
+		}
+
+		if next == -1 {
+			apply(&pending[0], TemporaryWorkspaceName(pending[0].ws.ID))
+			continue
+		}
+
+		apply(&pending[next], pending[next].newName)
+		pending = append(pending[:next], pending[next+1:]...)
+	}
+
+	return cmds
+}
+
+// TemporaryWorkspaceName returns a placeholder name that frees up a workspace's real name for the duration of a batch.
+func TemporaryWorkspaceName(workspaceID int) string {
+	return fmt.Sprintf("hypr-local-workspaces-tmp-%d", workspaceID)
+}
+
+// IsSpecialWorkspace reports whether ws is a special workspace (scratchpad). Named workspaces also have negative
+// IDs in Hyprland, so the name is what tells them apart.
+func IsSpecialWorkspace(ws WorkspaceDTO) bool {
+	return strings.HasPrefix(ws.Name, "special:")
+}
+
+func (n Naming) codec() Codec {
+	if n.Codec == nil {
+		return DefaultCodec
+	}
+
+	return n.Codec
+}
+
+// Name returns the name of the workspace at index.
+func (n Naming) Name(index int) (string, error) {
+	return EncodeWorkspaceName(n.codec(), n.Slot, index, n.Labels[index])
+}
+
+// Rename returns the name the workspace called current gets at index. A local name keeps its codec, which only
+// migrate changes. Without a label given for index, it keeps the label current shows, unless that label was
+// given for another position, so labels survive commands run without every --label.
+func (n Naming) Rename(current string, index int) (string, error) {
+	codec, _, _, err := decodeWorkspaceName(current)
+	if err != nil {
+		codec = n.codec()
+	}
+
+	label, ok := n.Labels[index]
+	if !ok {
+		label = n.keptLabel(current)
+	}
+
+	return EncodeWorkspaceName(codec, n.Slot, index, label)
+}
+
+// keptLabel returns the label a local workspace name, or one the user numbered like "2:code", shows, or "" if it
+// shows none or one given for a position.
+func (n Naming) keptLabel(name string) string {
+	visible := name
+	if _, _, _, err := decodeWorkspaceName(name); err == nil {
+		visible, _, _ = splitWorkspaceName(name)
+	} else if _, ok := workspacePosition(name); !ok {
+		return ""
+	}
+
+	_, label, _ := strings.Cut(visible, ":")
+	for _, given := range n.Labels {
+		if EscapeWorkspaceName(given) == label {
+			return ""
+		}
+	}
+
+	return label
+}
+
+// IsLocal reports whether name is a well-formed name of this naming, with any label or none.
+func (n Naming) IsLocal(name string) bool {
+	codec, _, index, err := decodeWorkspaceName(name)
+	if err != nil || codec != n.codec() {
+		return false
+	}
+
+	expected, err := n.codec().Encode(n.Slot, index)
+	if err != nil {
+		return false
+	}
+
+	position := strconv.Itoa(index + 1)
+	visible, suffix, _ := splitWorkspaceName(name)
+
+	return suffix == expected && (visible == position || strings.HasPrefix(visible, position+":"))
+}
+
+// codecInUse returns the codec of the first local name among workspaces, or nil if there is none.
+func codecInUse(workspaces []WorkspaceDTO) Codec {
+	for _, ws := range workspaces {
+		if codec, _, _, err := decodeWorkspaceName(ws.Name); err == nil {
+			return codec
 		}
 	}
 
 	return nil
 }
+
+// namingOf returns how names on monitorID are built according to namings, defaulting to encoding the ID itself.
+func namingOf(namings map[int]Naming, monitorID int) Naming {
+	if naming, ok := namings[monitorID]; ok {
+		return naming
+	}
+
+	return Naming{Slot: monitorID}
+}
+
+// GetAdoptionCmds returns the renames that bring foreign workspaces (plain numeric names, names from another
+// monitor, ...) matching filter into the local scheme of the monitor they are on. Adopted workspaces are appended
+// after the monitor's existing local workspaces in ID order, so nothing already local is renamed. namings maps
+// Hyprland monitor IDs to how names on them are built; nil encodes the IDs themselves.
+func GetAdoptionCmds(workspaces []WorkspaceDTO, namings map[int]Naming, filter func(ws WorkspaceDTO) bool) ([]DispatchCmd, error) {
+	nextIndex := map[int]int{}
+	var foreign []WorkspaceDTO
+
+	for _, ws := range workspaces {
+		// Special workspaces (scratchpads) are none of our business
+		if IsSpecialWorkspace(ws) {
+			continue
+		}
+
+		if !namingOf(namings, ws.MonitorID).IsLocal(ws.Name) {
+			foreign = append(foreign, ws)
+			continue
+		}
+
+		index, _ := GetZeroWidthNameToIndex(ws.Name)
+		if index+1 > nextIndex[ws.MonitorID] {
+			nextIndex[ws.MonitorID] = index + 1
+		}
+	}
+
+	sort.Slice(foreign, func(i, j int) bool {
+		return foreign[i].ID < foreign[j].ID
+	})
+
+	var cmds []DispatchCmd
+	for _, ws := range foreign {
+		if filter != nil && !filter(ws) {
+			continue
+		}
+
+		newName, err := namingOf(namings, ws.MonitorID).Name(nextIndex[ws.MonitorID])
+		if err != nil {
+			return nil, err
+		}
+
+		nextIndex[ws.MonitorID]++
+		cmds = append(cmds, RenameWorkspaceCmd(ws.ID, newName))
+	}
+
+	return cmds, nil
+}
+
+// GetMigrationCmds returns the renames that re-encode the local workspace names written with from in to. The
+// visible part, monitor slot and index of every name stay the same, so nothing moves or gets relabelled.
+func GetMigrationCmds(workspaces []WorkspaceDTO, from, to Codec) ([]DispatchCmd, error) {
+	var cmds []DispatchCmd
+	for _, ws := range workspaces {
+		codec, slot, index, err := decodeWorkspaceName(ws.Name)
+		if err != nil || codec != from {
+			continue
+		}
+
+		suffix, err := to.Encode(slot, index)
+		if err != nil {
+			return nil, err
+		}
+
+		visible, _, _ := splitWorkspaceName(ws.Name)
+		cmds = append(cmds, RenameWorkspaceCmd(ws.ID, visible+suffix))
+	}
+
+	return cmds, nil
+}
+
+// AdoptWorkspace renames a single workspace created outside the tool into the local scheme of its monitor.
+func AdoptWorkspace(ctx context.Context, action *Action, workspaceID int) error {
+	workspaces, err := action.hyprctl.GetWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	monitors, err := action.fetchMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	namings, err := action.monitorNamings(monitors, workspaces)
+	if err != nil {
+		return err
+	}
+
+	cmds, err := GetAdoptionCmds(workspaces, namings, func(ws WorkspaceDTO) bool {
+		return ws.ID == workspaceID
+	})
+	if err != nil {
+		return err
+	}
+
+	return dispatchBatch(ctx, action.dispatcher, cmds)
+}
+
+// AdoptForeignWorkspaces renames every workspace created outside the tool into the local scheme of its monitor.
+func AdoptForeignWorkspaces(ctx context.Context, action *Action) error {
+	workspaces, err := action.hyprctl.GetWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	monitors, err := action.fetchMonitors(ctx)
+	if err != nil {
+		return err
+	}
+
+	namings, err := action.monitorNamings(monitors, workspaces)
+	if err != nil {
+		return err
+	}
+
+	cmds, err := GetAdoptionCmds(workspaces, namings, nil)
+	if err != nil {
+		return err
+	}
+
+	return dispatchBatch(ctx, action.dispatcher, cmds)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -9,108 +10,67 @@ import (
 )
 
 func TestGetWorkspacesOnMonitorFiltersByMonitorID(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "ws-1", MonitorID: monitorID},
 		{ID: 3, Name: "ws-3", MonitorID: monitorID},
 	}
 
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		expected[0],
 		{ID: 2, Name: "ws-2", MonitorID: 7},
 		expected[1],
-	}, nil)
+	}
 
-	workspaces, err := GetWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Equal(t, expected, workspaces)
 }
 
 func TestGetWorkspacesOnMonitorReturnsEmptyList(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		{ID: 2, Name: "ws-2", MonitorID: 7},
-	}, nil)
+	}
 
-	workspaces, err := GetWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Empty(t, workspaces)
 }
 
-func TestGetWorkspacesOnMonitorPropagatesErrors(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
-	sentinelErr := errors.New("get workspaces failed")
-	hypr.On("GetWorkspaces").Return(([]WorkspaceDTO)(nil), sentinelErr)
-
-	workspaces, err := GetWorkspacesOnMonitor(hypr, 42)
-
-	assert.Nil(t, workspaces)
-	assert.ErrorIs(t, err, sentinelErr)
-}
-
 func TestGetSortedWorkspacesOnMonitorSortsByNameIgnoringZeroWidthChars(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		{ID: 3, Name: "3\u200b\u200d", MonitorID: monitorID},
 		{ID: 1, Name: "1\u200c\u200b", MonitorID: monitorID},
-		{ID: 5, Name: "5\u200f\u2064", MonitorID: monitorID},
+		{ID: 5, Name: "5\u200f\u200f", MonitorID: monitorID},
 		{ID: 4, Name: "10\u200b\u200c\u200c", MonitorID: monitorID},
 		{ID: 2, Name: "6\u200f\u2060", MonitorID: monitorID},
-	}, nil)
+	}
 
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "1\u200c\u200b", MonitorID: monitorID},
 		{ID: 3, Name: "3\u200b\u200d", MonitorID: monitorID},
-		{ID: 5, Name: "5\u200f\u2064", MonitorID: monitorID},
+		{ID: 5, Name: "5\u200f\u200f", MonitorID: monitorID},
 		{ID: 2, Name: "6\u200f\u2060", MonitorID: monitorID},
 		{ID: 4, Name: "10\u200b\u200c\u200c", MonitorID: monitorID},
 	}
 
-	workspaces, err := GetSortedWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetSortedWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Equal(t, expected, workspaces)
 }
 
-func TestGetSortedWorkspacesOnMonitorPropagatesErrorsFromGetWorkspacesOnMonitor(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
-	sentinelErr := errors.New("get workspaces failed")
-	hypr.On("GetWorkspaces").Return(([]WorkspaceDTO)(nil), sentinelErr)
-
-	workspaces, err := GetSortedWorkspacesOnMonitor(hypr, 42)
-
-	assert.Nil(t, workspaces)
-	assert.ErrorIs(t, err, sentinelErr)
-}
-
 func TestGetSortedWorkspacesOnMonitorSortsByIdWhenInvalidZeroWidthNames(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		{ID: 3, Name: "ws-1", MonitorID: monitorID},
 		{ID: 1, Name: "ws-2", MonitorID: monitorID},
 		{ID: 6, Name: "invalid-\xff", MonitorID: monitorID}, // Invalid UTF-8
 		{ID: 5, Name: "ws-3", MonitorID: monitorID},
 		{ID: 4, Name: "ws-\u200b3", MonitorID: monitorID},
 		{ID: 2, Name: "ws-10", MonitorID: monitorID},
-	}, nil)
+	}
 
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "ws-2", MonitorID: monitorID},
@@ -121,66 +81,53 @@ func TestGetSortedWorkspacesOnMonitorSortsByIdWhenInvalidZeroWidthNames(t *testi
 		{ID: 6, Name: "invalid-\xff", MonitorID: monitorID}, // Should be last due to ID fallback
 	}
 
-	workspaces, err := GetSortedWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetSortedWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Equal(t, expected, workspaces)
 }
 
 func TestGetSortedWorkspacesOnMonitorReturnsEmptyList(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		{ID: 2, Name: "ws-2", MonitorID: 7},
-	}, nil)
+	}
 
-	workspaces, err := GetSortedWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetSortedWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Empty(t, workspaces)
 }
 
 func TestGetSortedWorkspacesOnMonitorSortsByIdWhenInvalidNames(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		{ID: 3, Name: "", MonitorID: monitorID}, // Invalid UTF-8
 		{ID: 1, Name: "", MonitorID: monitorID}, // Invalid UTF-8
-	}, nil)
+	}
 
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "", MonitorID: monitorID},
 		{ID: 3, Name: "", MonitorID: monitorID},
 	}
 
-	workspaces, err := GetSortedWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetSortedWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Equal(t, expected, workspaces)
 }
 
 func TestGetSortedWorkspacesOnMonitorGuardsAgainstAtoiOverflow(t *testing.T) {
-	hypr := new(mockHyprctl)
-	defer hypr.AssertExpectations(t)
-
 	monitorID := 1
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	all := []WorkspaceDTO{
 		{ID: 3, Name: "99999999999999999999999999999999999999999999999999", MonitorID: monitorID}, // Overflow
 		{ID: 1, Name: "1", MonitorID: monitorID},
-	}, nil)
+	}
 
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "1", MonitorID: monitorID},
 		{ID: 3, Name: "99999999999999999999999999999999999999999999999999", MonitorID: monitorID},
 	}
 
-	workspaces, err := GetSortedWorkspacesOnMonitor(hypr, monitorID)
+	workspaces := GetSortedWorkspacesOnMonitor(all, monitorID)
 
-	require.NoError(t, err)
 	assert.Equal(t, expected, workspaces)
 }
 
@@ -218,20 +165,18 @@ func TestGetWorkspaceIndexOnList_EmptyList(t *testing.T) {
 }
 
 func TestCompactLocalWorkspacesOnMonitor_CompactsList(t *testing.T) {
-	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	monitorID := 0
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	snap := &Snapshot{Workspaces: []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
 		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
 		{ID: 10, Name: "6\u200b\u2061", MonitorID: 0},
 		{ID: 15, Name: "8\u200b\u2063", MonitorID: 0},
 		{ID: 4, Name: "1\u200c\u200b", MonitorID: 1},
 		{ID: 5, Name: "1\u200d\u200b", MonitorID: 2},
-	}, nil)
+	}}
 
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
@@ -240,106 +185,86 @@ func TestCompactLocalWorkspacesOnMonitor_CompactsList(t *testing.T) {
 		{ID: 15, Name: "4\u200b\u200e", MonitorID: 0},
 	}
 
-	dispatcher.On("RenameWorkspace", 3, expected[1].Name).Return(nil)
-	dispatcher.On("RenameWorkspace", 10, expected[2].Name).Return(nil)
-	dispatcher.On("RenameWorkspace", 15, expected[3].Name).Return(nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(3, expected[1].Name),
+		RenameWorkspaceCmd(10, expected[2].Name),
+		RenameWorkspaceCmd(15, expected[3].Name),
+	}).Return(nil)
 
-	action := &Action{hyprctl: hypr, dispatcher: dispatcher}
-	err := CompactLocalWorkspacesOnMonitor(action, monitorID, false)
+	action := &Action{dispatcher: dispatcher}
+	err := CompactLocalWorkspacesOnMonitor(context.Background(), action, snap, monitorID, false)
 
 	assert.NoError(t, err)
 }
 
-func TestCompactLocalWorkspacesOnMonitor_PropagatesErrors(t *testing.T) {
-	hypr := new(mockHyprctl)
-	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
-	defer dispatcher.AssertExpectations(t)
-
-	monitorID := 0
-	sentinelErr := errors.New("get workspaces failed")
-	hypr.On("GetWorkspaces").Return(([]WorkspaceDTO)(nil), sentinelErr)
-
-	action := &Action{hyprctl: hypr, dispatcher: dispatcher}
-	err := CompactLocalWorkspacesOnMonitor(action, monitorID, false)
-
-	assert.ErrorIs(t, err, sentinelErr)
-}
-
 func TestCompactLocalWorkspacesOnMonitor_PropagatesGetZeroWidthNameToIndexErrors(t *testing.T) {
-	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	monitorID := 0
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	snap := &Snapshot{Workspaces: []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
 		{ID: 3, Name: "invalid-\xff", MonitorID: 0}, // Invalid UTF-8
-	}, nil)
+	}}
 
-	action := &Action{hyprctl: hypr, dispatcher: dispatcher}
-	err := CompactLocalWorkspacesOnMonitor(action, monitorID, false)
+	action := &Action{dispatcher: dispatcher}
+	err := CompactLocalWorkspacesOnMonitor(context.Background(), action, snap, monitorID, false)
 
 	assert.Error(t, err)
 }
 
 func TestCompactLocalWorkspacesOnMonitor_HandlesNoRenameNeeded(t *testing.T) {
-	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	monitorID := 0
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	snap := &Snapshot{Workspaces: []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
 		{ID: 3, Name: "2\u200b\u200c", MonitorID: 0},
 		{ID: 10, Name: "3\u200b\u200d", MonitorID: 0},
 		{ID: 15, Name: "4\u200b\u200e", MonitorID: 0},
-	}, nil)
+	}}
 
-	action := &Action{hyprctl: hypr, dispatcher: dispatcher}
-	err := CompactLocalWorkspacesOnMonitor(action, monitorID, false)
+	action := &Action{dispatcher: dispatcher}
+	err := CompactLocalWorkspacesOnMonitor(context.Background(), action, snap, monitorID, false)
 
 	assert.NoError(t, err)
 }
 
 func TestCompactLocalWorkspacesOnMonitor_PropagatesRenameWorkspaceErrors(t *testing.T) {
-	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	monitorID := 0
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	snap := &Snapshot{Workspaces: []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
 		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
 		{ID: 10, Name: "6\u200b\u2061", MonitorID: 0},
-	}, nil)
+	}}
 
-	dispatcher.On("RenameWorkspace", 3, "2\u200b\u200c").Return(nil)
 	sentinelErr := errors.New("rename failed")
-	dispatcher.On("RenameWorkspace", 10, "3\u200b\u200d").Return(sentinelErr)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+		RenameWorkspaceCmd(10, "3\u200b\u200d"),
+	}).Return(sentinelErr)
 
-	action := &Action{hyprctl: hypr, dispatcher: dispatcher}
-	err := CompactLocalWorkspacesOnMonitor(action, monitorID, false)
+	action := &Action{dispatcher: dispatcher}
+	err := CompactLocalWorkspacesOnMonitor(context.Background(), action, snap, monitorID, false)
 
 	assert.ErrorIs(t, err, sentinelErr)
 }
 
 func TestCompactLocalWorkspacesOnMonitor_FixesWorkspaceNames(t *testing.T) {
-	hypr := new(mockHyprctl)
 	dispatcher := new(mockDispatcher)
-	defer hypr.AssertExpectations(t)
 	defer dispatcher.AssertExpectations(t)
 
 	monitorID := 0
-	hypr.On("GetWorkspaces").Return([]WorkspaceDTO{
+	snap := &Snapshot{Workspaces: []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
 		{ID: 3, Name: "invalid-\xff", MonitorID: 0}, // Invalid UTF-8
 		{ID: 10, Name: "3\u200b\u200d", MonitorID: 0},
 		{ID: 15, Name: "ws-4", MonitorID: 0}, // Non-zero-width name
-	}, nil)
+	}}
 
 	expected := []WorkspaceDTO{
 		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
@@ -348,15 +273,23 @@ func TestCompactLocalWorkspacesOnMonitor_FixesWorkspaceNames(t *testing.T) {
 		{ID: 15, Name: "4\u200b\u200e", MonitorID: 0},
 	}
 
-	dispatcher.On("RenameWorkspace", 3, expected[1].Name).Return(nil)
-	dispatcher.On("RenameWorkspace", 15, expected[3].Name).Return(nil)
+	dispatcher.On("Batch", []DispatchCmd{
+		RenameWorkspaceCmd(3, expected[1].Name),
+		RenameWorkspaceCmd(15, expected[3].Name),
+	}).Return(nil)
 
-	action := &Action{hyprctl: hypr, dispatcher: dispatcher}
-	err := CompactLocalWorkspacesOnMonitor(action, monitorID, true)
+	action := &Action{dispatcher: dispatcher}
+	err := CompactLocalWorkspacesOnMonitor(context.Background(), action, snap, monitorID, true)
 
 	assert.NoError(t, err)
 }
 
+func TestIsSpecialWorkspace(t *testing.T) {
+	assert.True(t, IsSpecialWorkspace(WorkspaceDTO{ID: -98, Name: "special:scratch"}))
+	assert.False(t, IsSpecialWorkspace(WorkspaceDTO{ID: -1337, Name: "web"}))
+	assert.False(t, IsSpecialWorkspace(WorkspaceDTO{ID: 1, Name: "1\u200b\u200b"}))
+}
+
 func ws(id int, name string, monitorID int, windows int) WorkspaceDTO {
 	return WorkspaceDTO{ID: id, Name: name, MonitorID: monitorID, WindowsCount: windows}
 }
@@ -480,3 +413,173 @@ func TestDecideTargetWorkspaceIndex_ClampHighStillScansForEmpties(t *testing.T)
 	assert.Equal(t, 4, targetIndex) // N
 	assert.True(t, compact)         // empty at index 2 between 1 and 3
 }
+
+func TestLimitTargetWorkspaceIndex(t *testing.T) {
+	assert.Equal(t, 3, LimitTargetWorkspaceIndex(3, 3, 0), "unlimited")
+	assert.Equal(t, 3, LimitTargetWorkspaceIndex(3, 3, 4), "room for one more")
+	assert.Equal(t, 2, LimitTargetWorkspaceIndex(3, 3, 3), "full monitor stays on its last workspace")
+	assert.Equal(t, 4, LimitTargetWorkspaceIndex(4, 6, 3), "existing workspaces past the limit stay reachable")
+	assert.Equal(t, 0, LimitTargetWorkspaceIndex(0, 0, 1), "empty list is left to the caller")
+}
+
+func TestGetCompactionCmds_ReencodesOtherMonitors(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200c\u200b", MonitorID: 0}, // Encodes slot 1
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	}
+
+	// Anything else would leave a later switch to the computed name creating a duplicate
+	cmds, err := GetCompactionCmds(sorted, Naming{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{RenameWorkspaceCmd(1, "1\u200b\u200b")}, cmds)
+}
+
+func TestGetCompactionCmds_FixNamesRenamesForeignNames(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 7, Name: "7", MonitorID: 0},
+	}
+
+	_, err := GetCompactionCmds(sorted, Naming{}, false)
+	assert.Error(t, err)
+
+	cmds, err := GetCompactionCmds(sorted, Naming{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{RenameWorkspaceCmd(7, "2\u200b\u200c")}, cmds)
+}
+
+func TestGetCompactionCmds_Labels(t *testing.T) {
+	naming := Naming{Labels: map[int]string{0: "web", 1: "code"}}
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1:mail\u200b\u200b", MonitorID: 0}, // Label from an older configuration
+		{ID: 3, Name: "3\u200b\u200d", MonitorID: 0},
+	}
+
+	cmds, err := GetCompactionCmds(sorted, naming, false)
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(1, "1:web\u200b\u200b"),
+		RenameWorkspaceCmd(3, "2:code\u200b\u200c"),
+	}, cmds)
+}
+
+func TestGetCompactionCmds_KeepsLabelsNotGiven(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 2, Name: "2:chat\u200b\u200c", MonitorID: 0},
+		{ID: 3, Name: "3:web\u200b\u200d", MonitorID: 0},
+	}
+
+	cmds, err := GetCompactionCmds(sorted, Naming{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(2, "1:chat\u200b\u200b"),
+		RenameWorkspaceCmd(3, "2:web\u200b\u200c"),
+	}, cmds, "labels from an earlier --label stay with their workspace")
+
+	// A label given for another position stays there
+	cmds, err = GetCompactionCmds(sorted, Naming{Labels: map[int]string{2: "web"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(2, "1:chat\u200b\u200b"),
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+	}, cmds)
+}
+
+func TestGetCompactionCmds_KeepsCodec(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\U000E0030\U000E0030", MonitorID: 0},
+		{ID: 3, Name: "3\U000E0030\U000E0032", MonitorID: 0},
+	}
+
+	cmds, err := GetCompactionCmds(sorted, Naming{Codec: ZeroWidthCodec}, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{RenameWorkspaceCmd(3, "2\U000E0030\U000E0031")}, cmds, "only the position changes")
+}
+
+func TestGetSwapCmds_WithoutCompactionTradesNames(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b"},
+		{ID: 2, Name: "3\u200b\u200d"},
+		{ID: 3, Name: "5\u200b\u200f"},
+	}
+
+	cmds, err := GetSwapCmds(sorted, Naming{Slot: 0}, 2, 0, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(1, TemporaryWorkspaceName(1)),
+		RenameWorkspaceCmd(3, "1\u200b\u200b"),
+		RenameWorkspaceCmd(1, "5\u200b\u200f"),
+	}, cmds)
+}
+
+func TestGetSwapCmds_WithCompactionClosesGaps(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b"},
+		{ID: 2, Name: "3\u200b\u200d"},
+		{ID: 3, Name: "5\u200b\u200f"},
+	}
+
+	cmds, err := GetSwapCmds(sorted, Naming{Slot: 0, Labels: map[int]string{0: "web"}}, 2, 0, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(2, "2\u200b\u200c"),
+		RenameWorkspaceCmd(1, "3\u200b\u200d"),
+		RenameWorkspaceCmd(3, "1:web\u200b\u200b"),
+	}, cmds, "the gap frees a name, so nothing has to step aside")
+}
+
+func TestGetMoveWorkspaceCmds_DownwardsShiftsOthersDown(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b"},
+		{ID: 2, Name: "2\u200b\u200c"},
+		{ID: 3, Name: "3\u200b\u200d"},
+	}
+
+	cmds, err := GetMoveWorkspaceCmds(sorted, Naming{Slot: 0}, 0, 2, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(1, TemporaryWorkspaceName(1)),
+		RenameWorkspaceCmd(2, "1\u200b\u200b"),
+		RenameWorkspaceCmd(3, "2\u200b\u200c"),
+		RenameWorkspaceCmd(1, "3\u200b\u200d"),
+	}, cmds)
+}
+
+func TestGetReorderCmds_UnchangedOrderRenamesNothing(t *testing.T) {
+	sorted := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b"},
+		{ID: 2, Name: "3\u200b\u200d"},
+	}
+
+	cmds, err := GetReorderCmds(sorted, sorted, Naming{Slot: 0}, false)
+	require.NoError(t, err)
+	assert.Empty(t, cmds)
+
+	cmds, err = GetReorderCmds(sorted, sorted, Naming{Slot: 0}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []DispatchCmd{RenameWorkspaceCmd(2, "2\u200b\u200c")}, cmds, "compaction still applies")
+}
+
+func TestGetMoveToMonitorCmds_InsertsWithoutCompactingSource(t *testing.T) {
+	source := []WorkspaceDTO{
+		{ID: 1, Name: "1\u200b\u200b", MonitorID: 0},
+		{ID: 2, Name: "2\u200b\u200c", MonitorID: 0},
+	}
+	target := []WorkspaceDTO{
+		{ID: 3, Name: "1\u200c\u200b", MonitorID: 1},
+		{ID: 4, Name: "2\u200c\u200c", MonitorID: 1},
+	}
+
+	cmds, newName, err := GetMoveToMonitorCmds(source, Naming{Slot: 0}, 0, target, Naming{Slot: 1}, 1, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, "2\u200c\u200c", newName)
+	assert.Equal(t, []DispatchCmd{
+		RenameWorkspaceCmd(4, "3\u200c\u200d"),
+		RenameWorkspaceCmd(1, "2\u200c\u200c"),
+	}, cmds)
+}
@@ -2,8 +2,6 @@ package main
 
 import (
 	"fmt"
-	"strconv"
-	"unicode/utf8"
 )
 
 var zeroWidthDigits = []rune{
@@ -19,73 +17,45 @@ var zeroWidthDigits = []rune{
 	'\u2064', // INVISIBLE PLUS
 }
 
-// GetZeroWidthNameFromIndex generates a unique workspace name using zero-width characters based on the monitor ID and workspace index.
-func GetZeroWidthNameFromIndex(monitorID, index int) (string, error) {
-	if monitorID < 0 || monitorID > len(zeroWidthDigits)-1 {
-		return "", fmt.Errorf("monitorID out of range: %d. A maximum of %d monitors is supported: ", monitorID, len(zeroWidthDigits)-1)
-	}
-
-	if index < 0 {
-		return "", fmt.Errorf("index must be non-negative: %d", index)
-	}
+// zeroWidthMonitorDelimiter (ZERO WIDTH NO-BREAK SPACE) brackets the monitor segment of monitors with IDs
+// above 9, whose ID doesn't fit a single zeroWidthDigits rune: "1" + FEFF + "1" + "2" + FEFF + ... for monitor 12.
+// Monitors 0..9 keep their single-rune marker, so names created before multi-digit IDs existed stay valid.
+const zeroWidthMonitorDelimiter = '\ufeff'
 
-	workspaceName := strconv.Itoa(index + 1)
-
-	// Prefix with monitor ID zero-width char to ensure uniqueness across monitors
-	workspaceName += string(zeroWidthDigits[monitorID])
-
-	// Append zero-width chars for each digit in the index
-	indexAsStr := strconv.Itoa(index)
-	for _, char := range indexAsStr {
-		charAsNum, err := strconv.Atoi(string(char))
-
-		// Should be impossible to hit this branch since we validated index above
-		if err != nil || charAsNum < 0 || charAsNum > 9 {
-			continue
-		}
-
-		workspaceName += string(zeroWidthDigits[charAsNum])
-	}
+// ZeroWidthCodec is the original scheme, made of zero-width and invisible formatting characters.
+var ZeroWidthCodec Codec = &digitCodec{name: "zero-width", digits: zeroWidthDigits, delimiter: zeroWidthMonitorDelimiter}
 
-	return workspaceName, nil
+// GetZeroWidthNameFromIndex generates a unique workspace name using zero-width characters based on the monitor ID and workspace index.
+func GetZeroWidthNameFromIndex(monitorID, index int) (string, error) {
+	return EncodeWorkspaceName(DefaultCodec, monitorID, index, "")
 }
 
 // GetZeroWidthNameToIndex extracts the workspace index from a zero-width named workspace. Returns -1 if the name is not in the expected format.
+// The index is read from the invisible suffix, so labels in the visible part don't get in the way. Names written
+// by any codec are understood.
 func GetZeroWidthNameToIndex(name string) (int, error) {
-	if name == "" {
-		return -1, fmt.Errorf("empty workspace name")
-	}
-
-	// Traverse name until we hit a zero-width char
-	lastDigitIndex := 0
-	for lastDigitIndex < len(name) {
-		char := name[lastDigitIndex]
-		if char < '0' || char > '9' {
-			break
-		}
-
-		lastDigitIndex++
-	}
+	_, _, index, err := decodeWorkspaceName(name)
+	return index, err
+}
 
-	if lastDigitIndex == 0 {
-		return -1, fmt.Errorf("workspace name does not start with a digit: %q", name)
+func decodeWorkspaceName(name string) (Codec, int, int, error) {
+	if name == "" {
+		return nil, -1, -1, fmt.Errorf("empty workspace name")
 	}
 
-	// Enforce that after the leading digits, the next rune is a zero-width marker
-	if lastDigitIndex >= len(name) {
-		return -1, fmt.Errorf("workspace name contains no zero-width characters: %q", name)
+	if name[0] < '0' || name[0] > '9' {
+		return nil, -1, -1, fmt.Errorf("workspace name does not start with a digit: %q", name)
 	}
 
-	if r, _ := utf8.DecodeRuneInString(name[lastDigitIndex:]); !((r >= 0x200B && r <= 0x200F) || (r >= 0x2060 && r <= 0x2064)) {
-		return -1, fmt.Errorf("workspace name contains no zero-width characters: %q", name)
+	_, suffix, codec := splitWorkspaceName(name)
+	if codec == nil {
+		return nil, -1, -1, fmt.Errorf("workspace name contains no zero-width characters: %q", name)
 	}
 
-	index, err := strconv.Atoi(name[:lastDigitIndex])
-
-	// Number passed to Atoi should always be valid. Guard against overflow just in case.
+	slot, index, err := codec.Decode(suffix)
 	if err != nil {
-		return -1, fmt.Errorf("parsing workspace name index: %w", err)
+		return nil, -1, -1, fmt.Errorf("workspace name %q: %w", name, err)
 	}
 
-	return index - 1, nil
+	return codec, slot, index, nil
 }
@@ -15,7 +15,8 @@ func TestGetZeroWidthNameFromIndex(t *testing.T) {
 		{0, 0, "1\u200b\u200b", false},
 		{1, 2, "3\u200c\u200d", false},
 		{9, 15, "16\u2064\u200c\u2060", false},
-		{10, 0, "", true},        // Unsupported monitorID
+		{10, 0, "1\ufeff\u200c\u200b\ufeff\u200b", false}, // Delimited monitor segment
+		{123, 11, "12\ufeff\u200c\u200d\u200e\ufeff\u200c\u200c", false},
 		{-1, 0, "", true},        // Invalid monitorID
 		{0, -1, "0\u200b", true}, // Invalid index
 	}
@@ -49,12 +50,21 @@ func TestGetZeroWidthNameToIndex(t *testing.T) {
 		shouldFail     bool
 	}{
 		{"1\u200b\u200b", 0, false},
-		{"3\u200c\u200d\u200b", 2, false},
+		{"3\u200c\u200d", 2, false},
+		{"2:web\u200b\u200c", 1, false},
+		{"3:10\u200b\u200d", 2, false}, // Digits in the label don't count
+		{"2:web", -1, true},
+		{"2\u200b", -1, true}, // No index after the monitor segment
 		{"-1\u200b", -1, true},
 		{"", -1, true},
 		{"abc", -1, true},
 		{"999999999999999999999999\u200b", -1, true},
 		{"10\u2000", -1, true},
+		{"1\ufeff\u200c\u200b\ufeff\u200b", 0, false},
+		{"12\ufeff\u200c\u200d\u200e\ufeff\u200c\u200c", 11, false},
+		{"1\ufeff\u200c\u200b", -1, true}, // Unterminated monitor segment
+		{"1\ufeff\ufeff\u200b", -1, true}, // Empty monitor segment
+		{"1\ufeffx\ufeff", -1, true},
 	}
 
 	for i, test := range tests {
@@ -75,3 +85,60 @@ func TestGetZeroWidthNameToIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestNaming_LabeledName(t *testing.T) {
+	tests := []struct {
+		monitorID int
+		index     int
+		label     string
+		expected  string
+	}{
+		{0, 1, "web", "2:web\u200b\u200c"},
+		{0, 1, "", "2\u200b\u200c"},
+		{12, 0, "a,b;c", "1:a\u201ab\u037ec\ufeff\u200c\u200d\ufeff\u200b"}, // Would break dispatcher arguments
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			naming := Naming{Slot: test.monitorID, Labels: map[int]string{test.index: test.label}}
+			name, err := naming.Name(test.index)
+			if err != nil || name != test.expected {
+				t.Fatalf("got %q, %v; want %q", name, err, test.expected)
+			}
+
+			index, err := GetZeroWidthNameToIndex(name)
+			if err != nil || index != test.index {
+				t.Fatalf("decoded %q to %d, %v; want %d", name, index, err, test.index)
+			}
+
+			if !naming.IsLocal(name) || (Naming{Slot: test.monitorID + 1}).IsLocal(name) {
+				t.Fatalf("%q is not local to exactly its own monitor", name)
+			}
+		})
+	}
+
+	// The position in front of the label must still match the index
+	if (Naming{}).IsLocal("3:web\u200b\u200c") || (Naming{}).IsLocal("2web\u200b\u200c") {
+		t.Fatal("accepted a name whose visible position doesn't match its index")
+	}
+}
+
+func TestZeroWidthNames_RoundTripForManyMonitors(t *testing.T) {
+	for _, monitorID := range []int{0, 9, 10, 42, 999} {
+		for _, index := range []int{0, 9, 10} {
+			name, err := GetZeroWidthNameFromIndex(monitorID, index)
+			if err != nil {
+				t.Fatalf("monitor %d index %d: %v", monitorID, index, err)
+			}
+
+			got, err := GetZeroWidthNameToIndex(name)
+			if err != nil || got != index {
+				t.Fatalf("monitor %d: decoded %q to %d, %v; want %d", monitorID, name, got, err, index)
+			}
+
+			if !(Naming{Slot: monitorID}).IsLocal(name) || (Naming{Slot: monitorID + 1}).IsLocal(name) {
+				t.Fatalf("monitor %d: %q is not local to exactly its own monitor", monitorID, name)
+			}
+		}
+	}
+}
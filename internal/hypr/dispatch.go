@@ -0,0 +1,55 @@
+package hypr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DispatchCmd is a single Hyprland dispatcher invocation, e.g. "renameworkspace 3 <name>".
+type DispatchCmd struct {
+	Dispatcher string   `json:"dispatcher"`
+	Args       []string `json:"args"`
+}
+
+// workspaceNameEscaper swaps the characters that would end a workspace name early in a dispatch for lookalikes:
+// commas separate the window of movetoworkspace ("name:...,address:...") and semicolons separate batched commands.
+var workspaceNameEscaper = strings.NewReplacer(
+	",", "\u201a", // SINGLE LOW-9 QUOTATION MARK
+	";", "\u037e", // GREEK QUESTION MARK
+)
+
+// EscapeWorkspaceName returns name as it can be passed to a dispatcher. Names generated from labels are escaped
+// the same way, so they match what Hyprland ends up calling the workspace.
+func EscapeWorkspaceName(name string) string {
+	return workspaceNameEscaper.Replace(name)
+}
+
+func GoToWorkspaceCmd(wsName string) DispatchCmd {
+	return DispatchCmd{Dispatcher: "workspace", Args: []string{fmt.Sprintf("name:%s", EscapeWorkspaceName(wsName))}}
+}
+
+func RenameWorkspaceCmd(id int, wsNewName string) DispatchCmd {
+	return DispatchCmd{Dispatcher: "renameworkspace", Args: []string{strconv.Itoa(id), EscapeWorkspaceName(wsNewName)}}
+}
+
+func FocusMonitorCmd(monitorId int) DispatchCmd {
+	return DispatchCmd{Dispatcher: "focusmonitor", Args: []string{strconv.Itoa(monitorId)}}
+}
+
+func MoveToWorkspaceCmd(wsName string) DispatchCmd {
+	return DispatchCmd{Dispatcher: "movetoworkspace", Args: []string{EscapeWorkspaceName(wsName)}}
+}
+
+func MoveAddrToWorkspaceCmd(wsName, windowAddr string) DispatchCmd {
+	return DispatchCmd{Dispatcher: "movetoworkspace", Args: []string{fmt.Sprintf("name:%s,address:%s", EscapeWorkspaceName(wsName), windowAddr)}}
+}
+
+func MoveWorkspaceToMonitorCmd(workspaceID, monitorID int) DispatchCmd {
+	return DispatchCmd{Dispatcher: "moveworkspacetomonitor", Args: []string{strconv.Itoa(workspaceID), strconv.Itoa(monitorID)}}
+}
+
+// String renders the command the way hyprctl expects it after the "dispatch" keyword.
+func (c DispatchCmd) String() string {
+	return strings.Join(append([]string{c.Dispatcher}, c.Args...), " ")
+}
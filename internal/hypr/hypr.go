@@ -0,0 +1,58 @@
+// Package hypr holds Hyprland's view of monitors, workspaces and windows, and the dispatcher invocations that
+// change it, shared by the CLI and the simulator its tests run against.
+package hypr
+
+type MonitorDTO struct {
+	ID              int
+	Name            string
+	Description     string
+	X               int // Top-left corner in the layout
+	Y               int
+	Focused         bool
+	ActiveWorkspace SimpleWorkspace
+}
+
+type WorkspaceDTO struct {
+	ID           int
+	Name         string
+	Monitor      string
+	MonitorID    int
+	WindowsCount int `json:"windows"`
+}
+
+type ClientDTO struct {
+	Address   string
+	Monitor   int
+	Workspace SimpleWorkspace
+}
+
+type SimpleWorkspace struct {
+	ID   int
+	Name string
+}
+
+// Snapshot is a consistent view of the compositor state an action needs, fetched in a single request.
+type Snapshot struct {
+	Monitors        []MonitorDTO
+	Workspaces      []WorkspaceDTO
+	Clients         []ClientDTO
+	ActiveWorkspace WorkspaceDTO
+	ActiveWindow    ClientDTO
+}
+
+// ClientsInWorkspace returns the clients on the given workspace.
+func (s *Snapshot) ClientsInWorkspace(workspaceID int) []ClientDTO {
+	return FilterClientsByWorkspace(s.Clients, workspaceID)
+}
+
+// FilterClientsByWorkspace returns the clients on the given workspace.
+func FilterClientsByWorkspace(clients []ClientDTO, workspaceID int) []ClientDTO {
+	var filtered []ClientDTO
+	for _, client := range clients {
+		if client.Workspace.ID == workspaceID {
+			filtered = append(filtered, client)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,565 @@
+// Package simulator is an in-memory Hyprland the tests run the CLI against.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
+)
+
+// Simulator is an in-memory compositor implementing the queries and dispatches of the CLI. It applies
+// dispatches with Hyprland's semantics, so tests can assert the resulting state instead of the exact calls that produced it:
+//   - "workspace name:X" focuses X, or creates it on the focused monitor. A workspace on another monitor is
+//     focused together with its monitor.
+//   - Workspaces created by name get negative IDs counting down from -1337, like Hyprland's named workspaces.
+//   - A workspace that is empty and not shown on any monitor is destroyed.
+//   - "movetoworkspace" follows the window, so the target becomes the focused workspace.
+//   - Batches apply every command even when one of them fails, and report all failures.
+type Simulator struct {
+	mu             sync.Mutex
+	order          Order
+	monitors       []*simMonitor
+	workspaces     []*simWorkspace
+	clients        []*simClient
+	focusedMonitor int
+	activeWindow   string
+	nextNamedID    int
+	nextAddress    int
+	failures       map[string]error
+
+	// Dispatched logs every dispatch in the order it was applied, batched or not.
+	Dispatched []hypr.DispatchCmd
+	// Requests counts round trips to the compositor, queries and dispatches alike.
+	Requests int
+}
+
+type simMonitor struct {
+	id              int
+	name            string
+	activeWorkspace int
+}
+
+type simWorkspace struct {
+	id        int
+	name      string
+	monitorID int
+}
+
+type simClient struct {
+	address     string
+	workspaceID int
+}
+
+// Order lists the workspaces on a monitor in local index order, e.g. by the position decoded from their names.
+type Order func(workspaces []hypr.WorkspaceDTO, monitorID int) []hypr.WorkspaceDTO
+
+// New returns a simulator without monitors. WorkspaceNames lists workspaces in order, or in the order Hyprland
+// reports them if order is nil.
+func New(order Order) *Simulator {
+	return &Simulator{
+		order:          order,
+		focusedMonitor: -1,
+		nextNamedID:    -1337,
+		failures:       map[string]error{},
+	}
+}
+
+// AddMonitor adds a monitor showing a fresh numbered workspace, as Hyprland does when a monitor is plugged in.
+// The first monitor added gets focus.
+func (s *Simulator) AddMonitor(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := len(s.monitors)
+	mon := &simMonitor{id: id, name: name}
+	s.monitors = append(s.monitors, mon)
+
+	ws := s.createWorkspaceLocked(s.nextNumberedIDLocked(), "", id)
+	ws.name = strconv.Itoa(ws.id)
+	mon.activeWorkspace = ws.id
+
+	if s.focusedMonitor == -1 {
+		s.focusedMonitor = id
+	}
+
+	return id
+}
+
+// AddWorkspace creates a named workspace on a monitor without focusing it and returns its ID.
+func (s *Simulator) AddWorkspace(monitorID int, name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createWorkspaceLocked(s.takeNamedIDLocked(), name, monitorID).id
+}
+
+// AddClient opens a window on a workspace without focusing it and returns its address.
+func (s *Simulator) AddClient(workspaceID int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextAddress++
+	address := fmt.Sprintf("0x%x", 0xa000+s.nextAddress)
+	s.clients = append(s.clients, &simClient{address: address, workspaceID: workspaceID})
+
+	return address
+}
+
+// Focus shows a workspace on its monitor and focuses both, like clicking it in a bar.
+func (s *Simulator) Focus(workspaceID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.focusWorkspaceLocked(s.workspaceLocked(workspaceID))
+}
+
+// FailDispatch makes every later dispatch of the given dispatcher (e.g. "renameworkspace") fail with err.
+func (s *Simulator) FailDispatch(dispatcher string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[dispatcher] = err
+}
+
+// WorkspaceNames returns the names of the workspaces on a monitor in local index order.
+func (s *Simulator) WorkspaceNames(monitorID int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaces := s.workspacesLocked()
+	if s.order != nil {
+		workspaces = s.order(workspaces, monitorID)
+	}
+
+	var names []string
+	for _, ws := range workspaces {
+		if ws.MonitorID != monitorID {
+			continue
+		}
+
+		names = append(names, ws.Name)
+	}
+
+	return names
+}
+
+// ClientWorkspace returns the name of the workspace a window is on.
+func (s *Simulator) ClientWorkspace(address string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.clients {
+		if c.address == address {
+			if ws := s.workspaceLocked(c.workspaceID); ws != nil {
+				return ws.name
+			}
+		}
+	}
+
+	return ""
+}
+
+// ActiveWorkspaceName returns the name of the focused workspace.
+func (s *Simulator) ActiveWorkspaceName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.activeWorkspaceLocked().Name
+}
+
+func (s *Simulator) GetMonitors(ctx context.Context) ([]hypr.MonitorDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return s.monitorsLocked(), nil
+}
+
+func (s *Simulator) GetWorkspaces(ctx context.Context) ([]hypr.WorkspaceDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return s.workspacesLocked(), nil
+}
+
+func (s *Simulator) GetClients(ctx context.Context) ([]hypr.ClientDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return s.clientsLocked(), nil
+}
+
+func (s *Simulator) GetClientsInWorkspace(ctx context.Context, workspaceID int) ([]hypr.ClientDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return hypr.FilterClientsByWorkspace(s.clientsLocked(), workspaceID), nil
+}
+
+func (s *Simulator) GetActiveWorkspace(ctx context.Context) (hypr.WorkspaceDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return s.activeWorkspaceLocked(), nil
+}
+
+func (s *Simulator) GetActiveWindow(ctx context.Context) (hypr.ClientDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return s.activeWindowLocked(), nil
+}
+
+func (s *Simulator) GetActiveMonitorID(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return s.focusedMonitor, nil
+}
+
+func (s *Simulator) GetSnapshot(ctx context.Context) (hypr.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+	return hypr.Snapshot{
+		Monitors:        s.monitorsLocked(),
+		Workspaces:      s.workspacesLocked(),
+		Clients:         s.clientsLocked(),
+		ActiveWorkspace: s.activeWorkspaceLocked(),
+		ActiveWindow:    s.activeWindowLocked(),
+	}, nil
+}
+
+func (s *Simulator) GoToWorkspace(ctx context.Context, wsName string) error {
+	return s.Batch(ctx, []hypr.DispatchCmd{hypr.GoToWorkspaceCmd(wsName)})
+}
+
+func (s *Simulator) RenameWorkspace(ctx context.Context, id int, wsNewName string) error {
+	return s.Batch(ctx, []hypr.DispatchCmd{hypr.RenameWorkspaceCmd(id, wsNewName)})
+}
+
+func (s *Simulator) FocusMonitor(ctx context.Context, monitorId int) error {
+	return s.Batch(ctx, []hypr.DispatchCmd{hypr.FocusMonitorCmd(monitorId)})
+}
+
+func (s *Simulator) MoveToWorkspace(ctx context.Context, wsName string) error {
+	return s.Batch(ctx, []hypr.DispatchCmd{hypr.MoveToWorkspaceCmd(wsName)})
+}
+
+func (s *Simulator) MoveAddrToWorkspace(ctx context.Context, wsName, windowAddr string) error {
+	return s.Batch(ctx, []hypr.DispatchCmd{hypr.MoveAddrToWorkspaceCmd(wsName, windowAddr)})
+}
+
+func (s *Simulator) Batch(ctx context.Context, cmds []hypr.DispatchCmd) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests++
+
+	var errs []error
+	for _, cmd := range cmds {
+		s.Dispatched = append(s.Dispatched, cmd)
+		if err := s.applyLocked(cmd); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cmd, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Simulator) applyLocked(cmd hypr.DispatchCmd) error {
+	if err := s.failures[cmd.Dispatcher]; err != nil {
+		return err
+	}
+
+	switch cmd.Dispatcher {
+	case "workspace":
+		return s.goToLocked(strings.TrimPrefix(cmd.Args[0], "name:"))
+
+	case "renameworkspace":
+		id, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+
+		ws := s.workspaceLocked(id)
+		if ws == nil {
+			return fmt.Errorf("no workspace with id %d", id)
+		}
+
+		ws.name = cmd.Args[1]
+		return nil
+
+	case "focusmonitor":
+		id, err := strconv.Atoi(cmd.Args[0])
+		if err != nil || id < 0 || id >= len(s.monitors) {
+			return fmt.Errorf("no monitor %q", cmd.Args[0])
+		}
+
+		s.focusWorkspaceLocked(s.workspaceLocked(s.monitors[id].activeWorkspace))
+		return nil
+
+	case "moveworkspacetomonitor":
+		id, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+
+		monitorID, err := strconv.Atoi(cmd.Args[1])
+		if err != nil || monitorID < 0 || monitorID >= len(s.monitors) {
+			return fmt.Errorf("no monitor %q", cmd.Args[1])
+		}
+
+		ws := s.workspaceLocked(id)
+		if ws == nil {
+			return fmt.Errorf("no workspace with id %d", id)
+		}
+
+		s.moveWorkspaceLocked(ws, monitorID)
+		return nil
+
+	case "movetoworkspace":
+		target, address, _ := strings.Cut(cmd.Args[0], ",address:")
+		if address == "" {
+			address = s.activeWindow
+		}
+
+		return s.moveLocked(strings.TrimPrefix(target, "name:"), address)
+
+	default:
+		return fmt.Errorf("unsupported dispatcher %q", cmd.Dispatcher)
+	}
+}
+
+func (s *Simulator) goToLocked(name string) error {
+	ws := s.workspaceByNameLocked(name)
+	if ws == nil {
+		ws = s.createWorkspaceLocked(s.takeNamedIDLocked(), name, s.focusedMonitor)
+	}
+
+	s.focusWorkspaceLocked(ws)
+	return nil
+}
+
+func (s *Simulator) moveLocked(name, address string) error {
+	var client *simClient
+	for _, c := range s.clients {
+		if c.address == address {
+			client = c
+		}
+	}
+
+	if client == nil {
+		return fmt.Errorf("no window with address %q", address)
+	}
+
+	target := s.workspaceByNameLocked(name)
+	if target == nil {
+		source := s.workspaceLocked(client.workspaceID)
+		target = s.createWorkspaceLocked(s.takeNamedIDLocked(), name, source.monitorID)
+	}
+
+	client.workspaceID = target.id
+	s.focusWorkspaceLocked(target)
+	s.activeWindow = client.address
+	s.destroyUnusedLocked()
+
+	return nil
+}
+
+// moveWorkspaceLocked moves ws to another monitor and shows it there. Like Hyprland, a monitor that was showing
+// ws falls back to another of its workspaces, or a fresh numbered one if it has none left.
+func (s *Simulator) moveWorkspaceLocked(ws *simWorkspace, monitorID int) {
+	source := s.monitors[ws.monitorID]
+	ws.monitorID = monitorID
+
+	if source.activeWorkspace == ws.id {
+		var fallback *simWorkspace
+		for _, other := range s.workspaces {
+			if other.monitorID == source.id {
+				fallback = other
+				break
+			}
+		}
+
+		if fallback == nil {
+			fallback = s.createWorkspaceLocked(s.nextNumberedIDLocked(), "", source.id)
+			fallback.name = strconv.Itoa(fallback.id)
+		}
+
+		source.activeWorkspace = fallback.id
+	}
+
+	if s.focusedMonitor == source.id {
+		s.focusWorkspaceLocked(ws)
+		return
+	}
+
+	s.monitors[monitorID].activeWorkspace = ws.id
+	s.destroyUnusedLocked()
+}
+
+// focusWorkspaceLocked shows ws on its monitor, focuses the monitor and destroys whatever became unused.
+func (s *Simulator) focusWorkspaceLocked(ws *simWorkspace) {
+	s.monitors[ws.monitorID].activeWorkspace = ws.id
+	s.focusedMonitor = ws.monitorID
+
+	s.activeWindow = ""
+	for _, c := range s.clients {
+		if c.workspaceID == ws.id {
+			s.activeWindow = c.address
+			break
+		}
+	}
+
+	s.destroyUnusedLocked()
+}
+
+func (s *Simulator) destroyUnusedLocked() {
+	kept := s.workspaces[:0]
+	for _, ws := range s.workspaces {
+		if s.monitors[ws.monitorID].activeWorkspace == ws.id || s.windowCountLocked(ws.id) > 0 {
+			kept = append(kept, ws)
+		}
+	}
+
+	s.workspaces = kept
+}
+
+func (s *Simulator) createWorkspaceLocked(id int, name string, monitorID int) *simWorkspace {
+	ws := &simWorkspace{id: id, name: name, monitorID: monitorID}
+	s.workspaces = append(s.workspaces, ws)
+
+	return ws
+}
+
+func (s *Simulator) takeNamedIDLocked() int {
+	id := s.nextNamedID
+	s.nextNamedID--
+
+	return id
+}
+
+func (s *Simulator) nextNumberedIDLocked() int {
+	id := 1
+	for s.workspaceLocked(id) != nil {
+		id++
+	}
+
+	return id
+}
+
+func (s *Simulator) workspaceLocked(id int) *simWorkspace {
+	for _, ws := range s.workspaces {
+		if ws.id == id {
+			return ws
+		}
+	}
+
+	return nil
+}
+
+// workspaceByNameLocked resolves a name the way Hyprland does: the first workspace carrying it wins.
+func (s *Simulator) workspaceByNameLocked(name string) *simWorkspace {
+	for _, ws := range s.workspaces {
+		if ws.name == name {
+			return ws
+		}
+	}
+
+	return nil
+}
+
+func (s *Simulator) monitorsLocked() []hypr.MonitorDTO {
+	monitors := make([]hypr.MonitorDTO, 0, len(s.monitors))
+	for _, mon := range s.monitors {
+		active := s.workspaceLocked(mon.activeWorkspace)
+		monitors = append(monitors, hypr.MonitorDTO{
+			ID:              mon.id,
+			Name:            mon.name,
+			Focused:         mon.id == s.focusedMonitor,
+			ActiveWorkspace: hypr.SimpleWorkspace{ID: active.id, Name: active.name},
+		})
+	}
+
+	return monitors
+}
+
+func (s *Simulator) workspacesLocked() []hypr.WorkspaceDTO {
+	workspaces := make([]hypr.WorkspaceDTO, 0, len(s.workspaces))
+	for _, ws := range s.workspaces {
+		workspaces = append(workspaces, s.workspaceDTOLocked(ws))
+	}
+
+	return workspaces
+}
+
+func (s *Simulator) workspaceDTOLocked(ws *simWorkspace) hypr.WorkspaceDTO {
+	return hypr.WorkspaceDTO{
+		ID:           ws.id,
+		Name:         ws.name,
+		Monitor:      s.monitors[ws.monitorID].name,
+		MonitorID:    ws.monitorID,
+		WindowsCount: s.windowCountLocked(ws.id),
+	}
+}
+
+func (s *Simulator) windowCountLocked(workspaceID int) int {
+	windows := 0
+	for _, c := range s.clients {
+		if c.workspaceID == workspaceID {
+			windows++
+		}
+	}
+
+	return windows
+}
+
+func (s *Simulator) clientsLocked() []hypr.ClientDTO {
+	clients := make([]hypr.ClientDTO, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, s.clientDTOLocked(c))
+	}
+
+	return clients
+}
+
+func (s *Simulator) clientDTOLocked(c *simClient) hypr.ClientDTO {
+	ws := s.workspaceLocked(c.workspaceID)
+
+	return hypr.ClientDTO{
+		Address:   c.address,
+		Monitor:   ws.monitorID,
+		Workspace: hypr.SimpleWorkspace{ID: ws.id, Name: ws.name},
+	}
+}
+
+func (s *Simulator) activeWorkspaceLocked() hypr.WorkspaceDTO {
+	if s.focusedMonitor < 0 {
+		return hypr.WorkspaceDTO{}
+	}
+
+	return s.workspaceDTOLocked(s.workspaceLocked(s.monitors[s.focusedMonitor].activeWorkspace))
+}
+
+func (s *Simulator) activeWindowLocked() hypr.ClientDTO {
+	for _, c := range s.clients {
+		if c.address == s.activeWindow {
+			return s.clientDTOLocked(c)
+		}
+	}
+
+	return hypr.ClientDTO{}
+}
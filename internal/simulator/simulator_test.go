@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKirtle/hypr-local-workspaces/internal/hypr"
+)
+
+func TestSimulator_GoToCreatesNamedWorkspaceOnFocusedMonitor(t *testing.T) {
+	sim := New(nil)
+	sim.AddMonitor("DP-1")
+	sim.AddMonitor("DP-2")
+
+	require.NoError(t, sim.GoToWorkspace(context.Background(), "2\u200b\u200c"))
+
+	snap, err := sim.GetSnapshot(context.Background())
+	require.NoError(t, err)
+
+	// The numbered workspace on DP-1 was empty and lost focus, so it is gone
+	assert.Equal(t, hypr.WorkspaceDTO{ID: -1337, Name: "2\u200b\u200c", Monitor: "DP-1", MonitorID: 0}, snap.ActiveWorkspace)
+	assert.Equal(t, []string{"2\u200b\u200c"}, sim.WorkspaceNames(0))
+	assert.Equal(t, []string{"2"}, sim.WorkspaceNames(1))
+}
+
+func TestSimulator_GoToFocusesWorkspaceOnAnotherMonitor(t *testing.T) {
+	sim := New(nil)
+	sim.AddMonitor("DP-1")
+	sim.AddMonitor("DP-2")
+
+	require.NoError(t, sim.GoToWorkspace(context.Background(), "2"))
+
+	id, err := sim.GetActiveMonitorID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, []string{"1"}, sim.WorkspaceNames(0), "the workspace shown on DP-1 survives")
+}
+
+func TestSimulator_MoveFollowsWindowAndDestroysEmptySource(t *testing.T) {
+	sim := New(nil)
+	sim.AddMonitor("DP-1")
+	ws := sim.AddWorkspace(0, "1\u200b\u200b")
+	window := sim.AddClient(ws)
+	sim.Focus(ws)
+
+	require.NoError(t, sim.MoveAddrToWorkspace(context.Background(), "2\u200b\u200c", window))
+
+	assert.Equal(t, "2\u200b\u200c", sim.ClientWorkspace(window))
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName())
+	assert.Equal(t, []string{"2\u200b\u200c"}, sim.WorkspaceNames(0))
+}
+
+func TestSimulator_BatchReportsEveryFailure(t *testing.T) {
+	sim := New(nil)
+	sim.AddMonitor("DP-1")
+	sim.FailDispatch("renameworkspace", assert.AnError)
+
+	err := sim.Batch(context.Background(), []hypr.DispatchCmd{
+		hypr.RenameWorkspaceCmd(1, "1\u200b\u200b"),
+		hypr.GoToWorkspaceCmd("2\u200b\u200c"),
+		hypr.RenameWorkspaceCmd(42, "x"),
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, "2\u200b\u200c", sim.ActiveWorkspaceName(), "later commands still apply")
+	assert.Len(t, sim.Dispatched, 3)
+	assert.Equal(t, 1, sim.Requests)
+}